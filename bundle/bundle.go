@@ -0,0 +1,186 @@
+// Package bundle prepares the web assets for release. It copies each asset
+// under a name that includes a hash of its content, rewrites the references
+// between assets to match, and adds gzip and brotli compressed variants. The
+// server can then tell browsers to cache the hashed assets forever, since a
+// new build gives any asset that changed a new name. It is used by the mage
+// build and has no dependency on mage.
+package bundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Fixed lists the assets that keep their names: the page, whose URL is the
+// app's, and the service worker, whose URL identifies it to the browser. They
+// mustn't be cached.
+var Fixed = map[string]bool{
+	"index.html": true,
+	"sw.js":      true,
+}
+
+// hashLength is the number of hex digits of the content hash in a hashed name.
+const hashLength = 12
+
+// compressible lists the extensions of the assets worth compressing.
+var compressible = map[string]bool{
+	".html":        true,
+	".css":         true,
+	".js":          true,
+	".json":        true,
+	".webmanifest": true,
+	".wasm":        true,
+	".svg":         true,
+	".txt":         true,
+}
+
+// HashedName returns name with the first hashLength hex digits of the SHA-256
+// of data inserted before its extension, e.g. "app.0123456789ab.wasm".
+func HashedName(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:hashLength] + ext
+}
+
+// Bundle reads the assets in srcDir and writes their release versions to
+// dstDir, which it creates or empties first. It returns the names the assets
+// were given, keyed by their original names. Only the files directly in
+// srcDir are assets, and a README.md there isn't.
+//
+// Assets refer to each other by absolute paths in double quotes, such as
+// src="/app.js" or fetch("/app.wasm"). An asset is hashed after the references
+// in it have been rewritten, so its name changes whenever one of the assets
+// it refers to does. The Fixed assets keep their names, and references to
+// them are left alone.
+func Bundle(srcDir, dstDir string) (names map[string]string, err error) {
+	contents := map[string][]byte{}
+	entries, err := ioutil.ReadDir(srcDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == "README.md" {
+			continue
+		}
+		contents[e.Name()], err = ioutil.ReadFile(path.Join(srcDir, e.Name()))
+		if err != nil {
+			return
+		}
+	}
+	names, err = rename(contents)
+	if err != nil {
+		return
+	}
+	err = os.RemoveAll(dstDir)
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(dstDir, 0755)
+	if err != nil {
+		return
+	}
+	for name, data := range contents {
+		err = write(path.Join(dstDir, names[name]), data)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// rename rewrites the references between the assets in contents and returns
+// the hashed name of each. Assets are hashed once every asset they refer to
+// has been, so references to assets that aren't Fixed can't be circular.
+func rename(contents map[string][]byte) (names map[string]string, err error) {
+	var pending []string
+	for name := range contents {
+		pending = append(pending, name)
+	}
+	sort.Strings(pending)
+	names = map[string]string{}
+	for len(pending) > 0 {
+		var waiting []string
+		for _, name := range pending {
+			if refersTo(contents[name], pending, name) {
+				waiting = append(waiting, name)
+				continue
+			}
+			for from, to := range names {
+				contents[name] = bytes.ReplaceAll(contents[name], quoted(from), quoted(to))
+			}
+			names[name] = name
+			if !Fixed[name] {
+				names[name] = HashedName(name, contents[name])
+			}
+		}
+		if len(waiting) == len(pending) {
+			err = fmt.Errorf("assets refer to each other in a circle: %s", strings.Join(waiting, ", "))
+			return
+		}
+		pending = waiting
+	}
+	return
+}
+
+// refersTo reports whether data refers to any of names other than self and
+// the Fixed assets.
+func refersTo(data []byte, names []string, self string) bool {
+	for _, name := range names {
+		if name != self && !Fixed[name] && bytes.Contains(data, quoted(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoted returns the reference to the named asset as it appears in another.
+func quoted(name string) []byte {
+	return []byte(`"/` + name + `"`)
+}
+
+// write writes data to fpath and, if the asset is compressible, gzip and
+// brotli compressed variants to fpath with ".gz" and ".br" appended. A
+// variant is left out if it isn't smaller than data.
+func write(fpath string, data []byte) (err error) {
+	err = ioutil.WriteFile(fpath, data, 0644)
+	if err != nil || !compressible[path.Ext(fpath)] {
+		return
+	}
+	var gz, br bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	if err != nil {
+		return
+	}
+	bw := brotli.NewWriterLevel(&br, brotli.BestCompression)
+	for _, w := range []io.WriteCloser{zw, bw} {
+		_, err = w.Write(data)
+		if err != nil {
+			return
+		}
+		err = w.Close()
+		if err != nil {
+			return
+		}
+	}
+	for ext, variant := range map[string][]byte{".gz": gz.Bytes(), ".br": br.Bytes()} {
+		if len(variant) >= len(data) {
+			continue
+		}
+		err = ioutil.WriteFile(fpath+ext, variant, 0644)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
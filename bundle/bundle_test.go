@@ -0,0 +1,137 @@
+package bundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestBundle(t *testing.T) {
+	src, dst := t.TempDir(), path.Join(t.TempDir(), "dist")
+	style := strings.Repeat("body { color: black; }\n", 20)
+	files := map[string]string{
+		"index.html": `<link rel="stylesheet" href="/style.css"><script src="/app.js"></script>`,
+		"app.js":     `fetch("/app.wasm"); fetch("/get");`,
+		"app.wasm":   "\x00asm",
+		"style.css":  style,
+		"README.md":  "not an asset",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(path.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Anything left in dst by an earlier build is removed.
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(dst, "stale.js"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := Bundle(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wasm := HashedName("app.wasm", []byte(files["app.wasm"]))
+	app := `fetch("/` + wasm + `"); fetch("/get");`
+	want := map[string]string{
+		"index.html": "index.html",
+		"app.wasm":   wasm,
+		"app.js":     HashedName("app.js", []byte(app)),
+		"style.css":  HashedName("style.css", []byte(style)),
+	}
+	if len(names) != len(want) {
+		t.Errorf("expected names for %d assets, got %v", len(want), names)
+	}
+	for name, hashed := range want {
+		if names[name] != hashed {
+			t.Errorf("%s: expected name %s, got %s", name, hashed, names[name])
+		}
+	}
+	read := func(name string) string {
+		b, err := ioutil.ReadFile(path.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	if got := read(names["app.js"]); got != app {
+		t.Errorf("app.js: expected %q, got %q", app, got)
+	}
+	page := `<link rel="stylesheet" href="/` + names["style.css"] + `"><script src="/` + names["app.js"] + `"></script>`
+	if got := read("index.html"); got != page {
+		t.Errorf("index.html: expected %q, got %q", page, got)
+	}
+
+	// style.css is big enough to compress, app.wasm isn't.
+	zr, err := gzip.NewReader(strings.NewReader(read(names["style.css"] + ".gz")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadAll(zr); err != nil || string(b) != style {
+		t.Errorf("style.css.gz: expected the stylesheet, got %q, %v", b, err)
+	}
+	b, err := ioutil.ReadAll(brotli.NewReader(bytes.NewReader([]byte(read(names["style.css"] + ".br")))))
+	if err != nil || string(b) != style {
+		t.Errorf("style.css.br: expected the stylesheet, got %q, %v", b, err)
+	}
+	for _, name := range []string{wasm + ".gz", wasm + ".br", "README.md", "stale.js"} {
+		if _, err := os.Stat(path.Join(dst, name)); err == nil {
+			t.Errorf("expected no %s", name)
+		}
+	}
+}
+
+func TestBundleCircular(t *testing.T) {
+	src := t.TempDir()
+	for name, data := range map[string]string{"a.js": `load("/b.js")`, "b.js": `load("/a.js")`} {
+		if err := ioutil.WriteFile(path.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Bundle(src, path.Join(t.TempDir(), "dist")); err == nil || !strings.Contains(err.Error(), "a.js, b.js") {
+		t.Errorf("expected an error naming a.js and b.js, got %v", err)
+	}
+}
+
+func TestHashedName(t *testing.T) {
+	if got := HashedName("app.wasm", []byte("x")); got != "app.2d711642b726.wasm" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestBundleFixed(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		"index.html": `<script src="/app.js"></script>`,
+		"app.js":     `register("/sw.js")`,
+		"sw.js":      `const SHELL = ["/", "/app.js"];`,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(path.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := Bundle(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := HashedName("app.js", []byte(files["app.js"]))
+	if names["sw.js"] != "sw.js" || names["app.js"] != app {
+		t.Fatalf("expected sw.js to keep its name and app.js to be hashed, got %v", names)
+	}
+	sw, err := ioutil.ReadFile(path.Join(dst, "sw.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `const SHELL = ["/", "/` + app + `"];`; string(sw) != want {
+		t.Errorf("sw.js: expected %q, got %q", want, sw)
+	}
+}
@@ -0,0 +1,61 @@
+// Command wasmskelgen runs the wasmskel code generator without mage. It reads
+// the parameter schema from a JSON file (see gen.LoadSchema) and writes the
+// generated files into the given directories. A typical go:generate directive,
+// placed in the common package, looks like
+//
+//	//go:generate go run ../cmd/wasmskelgen -schema ../schema.json -pkg example.com/app/common
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/Michael-F-Ellis/wasmskel/gen"
+)
+
+func main() {
+	schema := flag.String("schema", "schema.json", "JSON file defining the parameters")
+	pkg := flag.String("pkg", "", "import path of the common package (required)")
+	commonDir := flag.String("common", ".", "output directory for state_g.go")
+	serverDir := flag.String("server", "../server", "output directory for dispatch_g.go")
+	wasmDir := flag.String("wasm", "../wasm", "output directory for updater_g.go")
+	assetsDir := flag.String("assets", "../server/assets", "output directory for index.html")
+	worker := flag.Bool("worker", false, "run the wasm client in a Web Worker")
+	layoutFile := flag.String("layout", "", "JSON file arranging the page (default one panel with every parameter)")
+	localesFile := flag.String("locales", "", "JSON file listing the languages the page offers (default English, German and French)")
+	flag.Parse()
+
+	parms, err := gen.LoadSchema(*schema)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var layout gen.Layout
+	if *layoutFile != "" {
+		layout, err = gen.LoadLayout(*layoutFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	var locales []gen.Locale
+	if *localesFile != "" {
+		locales, err = gen.LoadLocales(*localesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	cfg := gen.Config{
+		Parms:        parms,
+		CommonDir:    *commonDir,
+		ServerDir:    *serverDir,
+		WasmDir:      *wasmDir,
+		AssetsDir:    *assetsDir,
+		CommonImport: *pkg,
+		Worker:       *worker,
+		Layout:       layout,
+		Locales:      locales,
+	}
+	err = gen.Generate(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
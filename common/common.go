@@ -2,7 +2,10 @@
 
 package common
 
-import "sync"
+import (
+	"fmt"
+	"sync"
+)
 
 var sMutex sync.Mutex
 
@@ -21,3 +24,9 @@ func (sp *State) DirectUpdate(f func(p *State)) {
 	defer sMutex.Unlock()
 	f(sp)
 }
+
+// UnsettableErr returns an err whose string value indicates an attempt to
+// set an unsettable variable
+func UnsettableErr(varName string) error {
+	return fmt.Errorf("%s is not settable", varName)
+}
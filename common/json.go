@@ -0,0 +1,642 @@
+// Helpers for the generated, reflection-free JSON methods of State, and the
+// small JSON API the wasm client uses in place of encoding/json, which
+// leans on reflection that TinyGo supports poorly.
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// appendFloat appends the JSON encoding of f to b, formatted as encoding/json
+// would format it.
+func appendFloat(b []byte, f float64) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return b, fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// Shorten e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+// appendInt appends the JSON encoding of n to b.
+func appendInt(b []byte, n int) []byte {
+	return strconv.AppendInt(b, int64(n), 10)
+}
+
+// appendBool appends the JSON encoding of v to b.
+func appendBool(b []byte, v bool) []byte {
+	return strconv.AppendBool(b, v)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends s to b as a quoted JSON string, escaped the way
+// encoding/json escapes it, including the characters that are unsafe in
+// HTML. Invalid UTF-8 becomes U+FFFD.
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' { // line terminators in javascript
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// errSyntax reports JSON that scanObject can't make sense of.
+var errSyntax = errors.New("json: malformed object")
+
+// scanObject calls field with each decoded key and raw value of the JSON
+// object in data, stopping at the first error field returns. It checks the
+// syntax of the whole object, values included, but not their types.
+func scanObject(data []byte, field func(key, value []byte) error) error {
+	i := skipSpace(data, 0)
+	if i == len(data) || data[i] != '{' {
+		return errSyntax
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return trailing(data, i+1)
+	}
+	for {
+		if i == len(data) || data[i] != '"' {
+			return errSyntax
+		}
+		end, err := skipString(data, i)
+		if err != nil {
+			return err
+		}
+		key, err := decodeKey(data[i:end])
+		if err != nil {
+			return err
+		}
+		i = skipSpace(data, end)
+		if i == len(data) || data[i] != ':' {
+			return errSyntax
+		}
+		i = skipSpace(data, i+1)
+		end, err = skipValue(data, i)
+		if err != nil {
+			return err
+		}
+		err = field(key, data[i:end])
+		if err != nil {
+			return err
+		}
+		i = skipSpace(data, end)
+		if i == len(data) {
+			return errSyntax
+		}
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case '}':
+			return trailing(data, i+1)
+		default:
+			return errSyntax
+		}
+	}
+}
+
+// trailing returns an error unless data holds only white space from i on.
+func trailing(data []byte, i int) error {
+	if skipSpace(data, i) != len(data) {
+		return errSyntax
+	}
+	return nil
+}
+
+// skipSpace returns the index of the first byte at or after i in data that
+// isn't JSON white space.
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipString returns the index just past the JSON string starting at i,
+// checking its escapes as encoding/json does.
+func skipString(data []byte, i int) (int, error) {
+	for j := i + 1; j < len(data); j++ {
+		switch c := data[j]; {
+		case c == '"':
+			return j + 1, nil
+		case c < 0x20:
+			return 0, errSyntax
+		case c == '\\':
+			j++
+			if j == len(data) {
+				return 0, errSyntax
+			}
+			switch data[j] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if _, ok := hex4(data, j+1); !ok {
+					return 0, errSyntax
+				}
+				j += 4
+			default:
+				return 0, errSyntax
+			}
+		}
+	}
+	return 0, errSyntax
+}
+
+// skipValue returns the index just past the JSON value starting at i,
+// checking its syntax as encoding/json does. Objects and arrays are followed
+// with a stack of the brackets that close those still open.
+func skipValue(data []byte, i int) (end int, err error) {
+	var open []byte // innermost last
+	for {
+		// A value starts at i
+		if i == len(data) {
+			return 0, errSyntax
+		}
+		switch c := data[i]; c {
+		case '"':
+			i, err = skipString(data, i)
+		case '{', '[':
+			closer := byte('}')
+			if c == '[' {
+				closer = ']'
+			}
+			i = skipSpace(data, i+1)
+			if i < len(data) && data[i] == closer {
+				i++ // empty
+				break
+			}
+			open = append(open, closer)
+			if c == '{' {
+				i, err = skipKey(data, i)
+				if err != nil {
+					return 0, err
+				}
+			}
+			continue
+		default:
+			i, err = skipLiteral(data, i)
+		}
+		if err != nil {
+			return 0, err
+		}
+		// and is followed by the next member or element, or by the ends of
+		// the objects and arrays it completes.
+	next:
+		for {
+			if len(open) == 0 {
+				return i, nil
+			}
+			i = skipSpace(data, i)
+			if i == len(data) {
+				return 0, errSyntax
+			}
+			switch data[i] {
+			case ',':
+				i = skipSpace(data, i+1)
+				if open[len(open)-1] == '}' {
+					i, err = skipKey(data, i)
+					if err != nil {
+						return 0, err
+					}
+				}
+				break next
+			case open[len(open)-1]:
+				open = open[:len(open)-1]
+				i++
+			default:
+				return 0, errSyntax
+			}
+		}
+	}
+}
+
+// skipKey returns the index of the value of the object member whose key
+// starts at i.
+func skipKey(data []byte, i int) (int, error) {
+	if i == len(data) || data[i] != '"' {
+		return 0, errSyntax
+	}
+	i, err := skipString(data, i)
+	if err != nil {
+		return 0, err
+	}
+	i = skipSpace(data, i)
+	if i == len(data) || data[i] != ':' {
+		return 0, errSyntax
+	}
+	return skipSpace(data, i+1), nil
+}
+
+// skipLiteral returns the index just past the number, true, false or null
+// starting at i.
+func skipLiteral(data []byte, i int) (int, error) {
+	end := i
+	for end < len(data) && !bytes.ContainsRune([]byte(",}] \t\r\n"), rune(data[end])) {
+		end++
+	}
+	if !isLiteral(data[i:end]) {
+		return 0, errSyntax
+	}
+	return end, nil
+}
+
+// isNull reports whether raw is the JSON literal null. Like encoding/json,
+// the decode functions leave the destination unchanged when it is.
+func isNull(raw []byte) bool {
+	return string(raw) == "null"
+}
+
+// isNumber reports whether raw is a number in JSON syntax, which is stricter
+// than strconv's.
+func isNumber(raw []byte) bool {
+	i := 0
+	if i < len(raw) && raw[i] == '-' {
+		i++
+	}
+	digits := func() (n int) {
+		for i < len(raw) && raw[i] >= '0' && raw[i] <= '9' {
+			i++
+			n++
+		}
+		return
+	}
+	switch {
+	case i < len(raw) && raw[i] == '0':
+		i++
+	case digits() == 0:
+		return false
+	}
+	if i < len(raw) && raw[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(raw) && (raw[i] == 'e' || raw[i] == 'E') {
+		i++
+		if i < len(raw) && (raw[i] == '+' || raw[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(raw)
+}
+
+// typeError describes a value that doesn't suit the State field it's for.
+// For well formed values it uses the same words as encoding/json, which
+// names the JSON type of the value and gives the value only of a number
+// that doesn't fit a numeric field.
+func typeError(raw []byte, name, typ string) error {
+	var kind string
+	switch {
+	case len(raw) == 0:
+		kind = "empty value"
+	case raw[0] == '"':
+		kind = "string"
+	case raw[0] == '{':
+		kind = "object"
+	case raw[0] == '[':
+		kind = "array"
+	case string(raw) == "true" || string(raw) == "false":
+		kind = "bool"
+	case isNumber(raw) && (typ == "int" || typ == "float64"):
+		kind = "number " + string(raw)
+	case isNumber(raw):
+		kind = "number"
+	default:
+		kind = "invalid value " + string(raw)
+	}
+	return fmt.Errorf("json: cannot unmarshal %s into Go struct field State.%s of type %s", kind, name, typ)
+}
+
+// decodeFloat decodes raw into the float64 field called name.
+func decodeFloat(raw []byte, v *float64, name string) error {
+	if isNull(raw) {
+		return nil
+	}
+	if !isNumber(raw) {
+		return typeError(raw, name, "float64")
+	}
+	f, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return typeError(raw, name, "float64")
+	}
+	*v = f
+	return nil
+}
+
+// decodeInt decodes raw into the int field called name.
+func decodeInt(raw []byte, v *int, name string) error {
+	if isNull(raw) {
+		return nil
+	}
+	if !isNumber(raw) {
+		return typeError(raw, name, "int")
+	}
+	n, err := strconv.ParseInt(string(raw), 10, strconv.IntSize)
+	if err != nil {
+		return typeError(raw, name, "int")
+	}
+	*v = int(n)
+	return nil
+}
+
+// decodeBool decodes raw into the bool field called name.
+func decodeBool(raw []byte, v *bool, name string) error {
+	switch string(raw) {
+	case "null":
+	case "true":
+		*v = true
+	case "false":
+		*v = false
+	default:
+		return typeError(raw, name, "bool")
+	}
+	return nil
+}
+
+// decodeStringField decodes raw into the string field called name.
+func decodeStringField(raw []byte, v *string, name string) error {
+	if isNull(raw) {
+		return nil
+	}
+	s, err := decodeString(raw)
+	if err != nil {
+		return typeError(raw, name, "string")
+	}
+	*v = s
+	return nil
+}
+
+// decodeKey returns the value of the JSON string raw, an object key, without
+// copying it unless it holds escapes.
+func decodeKey(raw []byte) (key []byte, err error) {
+	if len(raw) >= 2 && bytes.IndexByte(raw, '\\') < 0 {
+		return raw[1 : len(raw)-1], nil
+	}
+	s, err := decodeString(raw)
+	return []byte(s), err
+}
+
+// decodeString returns the value of the JSON string raw. Strings without
+// escapes, the usual case, are converted directly. Like encoding/json, it
+// turns invalid UTF-8 into U+FFFD.
+func decodeString(raw []byte) (s string, err error) {
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' {
+		return "", errSyntax
+	}
+	inner := raw[1 : len(raw)-1]
+	plain := bytes.IndexByte(inner, '\\') < 0 && utf8.Valid(inner)
+	for _, c := range inner {
+		if c < 0x20 || c == '"' {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return string(inner), nil
+	}
+	return unescape(inner)
+}
+
+// unescape returns the text of inner, the contents of a JSON string, with
+// its escapes replaced.
+func unescape(inner []byte) (string, error) {
+	b := make([]byte, 0, len(inner))
+	for i := 0; i < len(inner); {
+		c := inner[i]
+		switch {
+		case c < 0x20 || c == '"':
+			return "", errSyntax
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(inner[i:])
+			b = append(b, string(r)...) // RuneError for invalid UTF-8
+			i += size
+			continue
+		case c != '\\':
+			b = append(b, c)
+			i++
+			continue
+		}
+		if i+1 == len(inner) {
+			return "", errSyntax
+		}
+		switch e := inner[i+1]; e {
+		case '"', '\\', '/':
+			b = append(b, e)
+		case 'b':
+			b = append(b, '\b')
+		case 'f':
+			b = append(b, '\f')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case 'u':
+			r, ok := hex4(inner, i+2)
+			if !ok {
+				return "", errSyntax
+			}
+			i += 4
+			if utf16.IsSurrogate(r) {
+				// A surrogate must be followed by its other half
+				r2, ok := hex4(inner, i+4)
+				if ok && i+3 < len(inner) && inner[i+2] == '\\' && inner[i+3] == 'u' {
+					if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+						r = dec
+						i += 6
+					} else {
+						r = utf8.RuneError
+					}
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			b = append(b, string(r)...)
+		default:
+			return "", errSyntax
+		}
+		i += 2
+	}
+	return string(b), nil
+}
+
+// hex4 returns the rune given by the 4 hex digits at inner[i:], if there are
+// 4.
+func hex4(inner []byte, i int) (r rune, ok bool) {
+	if i+4 > len(inner) {
+		return
+	}
+	n, err := strconv.ParseUint(string(inner[i:i+4]), 16, 32)
+	if err != nil {
+		return
+	}
+	return rune(n), true
+}
+
+// isLiteral reports whether raw is a JSON number, true, false or null.
+func isLiteral(raw []byte) bool {
+	switch string(raw) {
+	case "true", "false", "null":
+		return true
+	}
+	return isNumber(raw)
+}
+
+// ScanObject calls field with each key and raw value of the JSON object in
+// data, stopping at the first error field returns. Values are checked only
+// for syntax, and left for DecodeParm or State.Apply to decode.
+func ScanObject(data []byte, field func(key string, value []byte) error) error {
+	return scanObject(data, func(key, value []byte) error {
+		return field(string(key), value)
+	})
+}
+
+// DecodeObject decodes a JSON object whose values are null, booleans,
+// numbers or strings into a map, the way encoding/json would decode it into
+// a map[string]interface{}: numbers become float64 and null becomes nil. It
+// rejects objects with other values.
+func DecodeObject(data []byte) (m map[string]interface{}, err error) {
+	m = map[string]interface{}{}
+	err = scanObject(data, func(key, value []byte) error {
+		var v interface{}
+		switch {
+		case isNull(value):
+		case string(value) == "true" || string(value) == "false":
+			v = string(value) == "true"
+		case isNumber(value):
+			f, err := strconv.ParseFloat(string(value), 64)
+			if err != nil {
+				return fmt.Errorf("json: cannot decode number %s", value)
+			}
+			v = f
+		case value[0] == '"':
+			s, err := decodeString(value)
+			if err != nil {
+				return err
+			}
+			v = s
+		default:
+			return fmt.Errorf("json: unsupported value %s for key %s", value, key)
+		}
+		m[string(key)] = v
+		return nil
+	})
+	if err != nil {
+		m = nil
+	}
+	return
+}
+
+// DecodeString returns the value of the JSON string raw.
+func DecodeString(raw []byte) (string, error) {
+	return decodeString(raw)
+}
+
+// RawJSON is JSON that EncodeObject copies into its output as it is.
+type RawJSON []byte
+
+// EncodeObject returns the JSON encoding of m, with its keys in order as
+// encoding/json would give them. The values must be nil, bools, ints,
+// float64s, strings or RawJSON.
+func EncodeObject(m map[string]interface{}) (b []byte, err error) {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(appendString(b, k), ':')
+		switch v := m[k].(type) {
+		case nil:
+			b = append(b, "null"...)
+		case bool:
+			b = appendBool(b, v)
+		case int:
+			b = appendInt(b, v)
+		case float64:
+			b, err = appendFloat(b, v)
+			if err != nil {
+				return nil, err
+			}
+		case string:
+			b = appendString(b, v)
+		case RawJSON:
+			b = append(b, v...)
+		default:
+			return nil, fmt.Errorf("json: unsupported type %T for key %s", v, k)
+		}
+	}
+	return append(b, '}'), nil
+}
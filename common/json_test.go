@@ -0,0 +1,161 @@
+package common
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAppendFloat(t *testing.T) {
+	for _, f := range []float64{0, -0.5, 1, 12.5, 1e20, 1e21, 1.5e-7, 1e-6, -3e-9, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+		want, _ := json.Marshal(f)
+		got, err := appendFloat(nil, f)
+		if err != nil || string(got) != string(want) {
+			t.Errorf("appendFloat(%v) gave %s, %v; want %s", f, got, err, want)
+		}
+	}
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := appendFloat(nil, f); err == nil {
+			t.Errorf("appendFloat(%v) gave no error", f)
+		}
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	for _, s := range []string{"", "plain", `q"uote\`, "tab\tnl\ncr\r", "\x00\x1f", "<a&b>", "é ü 😀", "  ", "bad\xffutf8"} {
+		want, _ := json.Marshal(s)
+		if got := appendString(nil, s); string(got) != string(want) {
+			t.Errorf("appendString(%q) gave %s, want %s", s, got, want)
+		}
+	}
+}
+
+func TestScanObject(t *testing.T) {
+	data := ` { "a" : 1, "b!":"x,}", "c":{"d":[1,{"e":"]"}]}, "f":null } `
+	want := map[string]string{"a": "1", "b!": `"x,}"`, "c": `{"d":[1,{"e":"]"}]}`, "f": "null"}
+	got := map[string]string{}
+	err := scanObject([]byte(data), func(key, value []byte) error {
+		got[string(key)] = string(value)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("key %s: got %s, want %s", k, got[k], v)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d keys, want %d", len(got), len(want))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var f float64
+	var n int
+	var b bool
+	var s string
+	for _, bad := range []string{"", "-", "01", "1.", ".5", "1e", "+1", "0x10", "Inf", "NaN", "1_0", `"1"`} {
+		if decodeFloat([]byte(bad), &f, "F") == nil {
+			t.Errorf("decodeFloat accepted %q", bad)
+		}
+	}
+	if err := decodeFloat([]byte("-1.5e2"), &f, "F"); err != nil || f != -150 {
+		t.Errorf("decodeFloat gave %v, %v", f, err)
+	}
+	if decodeInt([]byte("1.5"), &n, "N") == nil || decodeInt([]byte("1e2"), &n, "N") == nil {
+		t.Errorf("decodeInt accepted a fraction or exponent")
+	}
+	if err := decodeInt([]byte("-42"), &n, "N"); err != nil || n != -42 {
+		t.Errorf("decodeInt gave %v, %v", n, err)
+	}
+	if decodeBool([]byte("1"), &b, "B") == nil {
+		t.Errorf("decodeBool accepted 1")
+	}
+	if err := decodeStringField([]byte(`"a\"bé"`), &s, "S"); err != nil || s != `a"bé` {
+		t.Errorf("decodeStringField gave %q, %v", s, err)
+	}
+	// null leaves the value alone
+	if decodeFloat([]byte("null"), &f, "F") != nil || decodeInt([]byte("null"), &n, "N") != nil ||
+		decodeBool([]byte("null"), &b, "B") != nil || decodeStringField([]byte("null"), &s, "S") != nil {
+		t.Errorf("null was rejected")
+	}
+	if f != -150 || n != -42 || b || s != `a"bé` {
+		t.Errorf("null changed a value")
+	}
+}
+
+func TestTypeError(t *testing.T) {
+	var s struct {
+		State struct {
+			Count int
+			Mode  string
+		}
+	}
+	for _, tc := range []struct{ raw, name, typ string }{
+		{`"x"`, "Count", "int"},
+		{`1.5`, "Count", "int"},
+		{`[1]`, "Count", "int"},
+		{`{}`, "Mode", "string"},
+		{`true`, "Mode", "string"},
+		{`12`, "Mode", "string"},
+	} {
+		want := json.Unmarshal([]byte(`{"State":{"`+tc.name+`":`+tc.raw+`}}`), &s)
+		got := typeError([]byte(tc.raw), tc.name, tc.typ)
+		// encoding/json names the anonymous outer struct with ""
+		if want == nil || got.Error() != strings.Replace(want.Error(), ".State.", "State.", 1) {
+			t.Errorf("typeError(%s) = %v, want %v", tc.raw, got, want)
+		}
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	for _, raw := range []string{`"plain"`, `"q\"\\\/"`, `"\b\f\n\r\t"`, `"é€"`, `"😀"`, `"\ud83d"`, `"\ud83dx"`, `"\ud83dA"`, "\"bad\xffutf8\""} {
+		var want string
+		json.Unmarshal([]byte(raw), &want)
+		if got, err := DecodeString([]byte(raw)); err != nil || got != want {
+			t.Errorf("DecodeString(%s) gave %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, bad := range []string{`"\x"`, `"\u12"`, `"\`, "\"a\tb\"", `"a"b"`} {
+		if _, err := DecodeString([]byte(bad)); err == nil {
+			t.Errorf("DecodeString accepted %s", bad)
+		}
+	}
+}
+
+func TestDecodeObject(t *testing.T) {
+	data := `{"Err": null, "N": -1.5e2, "B": true, "S": "a\nb"}`
+	var want map[string]interface{}
+	json.Unmarshal([]byte(data), &want)
+	got, err := DecodeObject([]byte(data))
+	if err != nil || len(got) != len(want) {
+		t.Fatalf("DecodeObject gave %v, %v; want %v", got, err, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("key %s: got %v, want %v", k, got[k], v)
+		}
+	}
+	if _, err := DecodeObject([]byte(`{"A": [1]}`)); err == nil {
+		t.Errorf("DecodeObject accepted an array value")
+	}
+}
+
+func TestEncodeObject(t *testing.T) {
+	m := map[string]interface{}{"b": true, "a": nil, "n": 42, "f": 1.5, "s": `q"`, "r": RawJSON(`{"x":1}`)}
+	got, err := EncodeObject(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m["r"] = json.RawMessage(m["r"].(RawJSON))
+	want, _ := json.Marshal(m)
+	if string(got) != string(want) {
+		t.Errorf("EncodeObject gave %s, want %s", got, want)
+	}
+	if _, err := EncodeObject(map[string]interface{}{"x": []int{1}}); err == nil {
+		t.Errorf("EncodeObject accepted a slice")
+	}
+}
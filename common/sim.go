@@ -0,0 +1,14 @@
+package common
+
+import "time"
+
+// SimInterval is the time between steps of the simulated back-end process.
+const SimInterval = time.Second
+
+// SimStep changes a State the way one step of the simulated back-end process
+// does. The server applies it to the shared State, and the wasm client's
+// simulation mode to a local one.
+func SimStep(p *State) {
+	p.Alpha += 1
+	p.Beta += 2
+}
@@ -0,0 +1,83 @@
+package gen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// The page has no inline scripts, styles or event handler attributes, so the
+// server can send a Content-Security-Policy that allows only its own files.
+// The stylesheet and scripts are generated into these assets.
+
+// loaderJS is the javascript for app.js when the wasm client runs on the
+// page's main thread. The client wires the page's controls itself.
+const loaderJS = `// Code generated by wasmskel/gen. DO NOT EDIT.
+// Load and launch our wasm component
+const go = new Go();
+WebAssembly.instantiateStreaming(fetch("/app.wasm"), go.importObject).then((result) => {
+    go.run(result.instance);
+});
+`
+
+// ContentSecurityPolicy returns the policy the server sends with every
+// response. Scripts, styles and connections are limited to the server's own
+// files and endpoints. Compiling the wasm client needs 'wasm-unsafe-eval'.
+// Workers, including the service worker, and the manifest must come from the
+// server too.
+func ContentSecurityPolicy(cfg Config) string {
+	return strings.Join([]string{
+		"default-src 'none'",
+		"script-src 'self' 'wasm-unsafe-eval'",
+		"style-src 'self'",
+		"img-src 'self'",
+		"connect-src 'self'",
+		"worker-src 'self'",
+		"manifest-src 'self'",
+		"base-uri 'none'",
+		"form-action 'none'",
+		"frame-ancestors 'none'",
+	}, "; ")
+}
+
+// genStyleSheet generates style.css in cfg.AssetsDir
+func genStyleSheet(cfg Config) (f File, err error) {
+	f.Path = path.Join(cfg.AssetsDir, "style.css")
+	f.Data = []byte(IndexCSS(cfg.themes()))
+	return
+}
+
+// genThemeScript generates theme.js in cfg.AssetsDir
+func genThemeScript(cfg Config) (f File, err error) {
+	f.Path = path.Join(cfg.AssetsDir, "theme.js")
+	f.Data = []byte(ThemeJS(cfg.themes()))
+	return
+}
+
+// genAppScript generates app.js in cfg.AssetsDir, the script that starts the
+// wasm client and registers the service worker.
+func genAppScript(cfg Config) (f File, err error) {
+	f.Path = path.Join(cfg.AssetsDir, "app.js")
+	f.Data = []byte(loaderJS + registerJS)
+	if cfg.Worker {
+		f.Data = []byte(WorkerPageJS + registerJS)
+	}
+	return
+}
+
+// genPolicy generates policy_g.go in cfg.ServerDir, which holds the
+// Content-Security-Policy for the generated page.
+func genPolicy(cfg Config) (f File, err error) {
+	tmpl := fmt.Sprintf(`
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package main
+
+	// contentSecurityPolicy is sent with every response. The generated page
+	// needs nothing it doesn't allow.
+	const contentSecurityPolicy = %q
+	`, ContentSecurityPolicy(cfg))
+	f.Path = path.Join(cfg.ServerDir, "policy_g.go")
+	f.Data, err = source("policy_g.go", tmpl, cfg.data())
+	return
+}
@@ -0,0 +1,146 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLError lists every problem CheckHTML found in a page.
+type HTMLError []string
+
+func (e HTMLError) Error() string {
+	return "invalid html:\n\t" + strings.Join(e, "\n\t")
+}
+
+// CheckHTML tokenizes page and checks that every attribute value is properly
+// quoted, that no two elements have the same id and that there is an element
+// with each of the ids in want. It returns an HTMLError describing all the
+// problems found, or nil.
+func CheckHTML(page []byte, want []string) error {
+	var problems HTMLError
+	var order []string // ids in page order
+	ids := map[string]int{}
+	z := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// A tag that runs to the end of the page may hide a missing quote
+			if raw := string(z.Raw()); z.Err() == io.EOF && strings.HasPrefix(raw, "<") {
+				problems = append(problems, fmt.Sprintf("unterminated tag %s", raw))
+				for _, p := range checkQuotes(raw) {
+					problems = append(problems, fmt.Sprintf("%s in %s", p, raw))
+				}
+			} else if z.Err() != io.EOF {
+				problems = append(problems, z.Err().Error())
+			}
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		raw := string(z.Raw())
+		for _, p := range checkQuotes(raw) {
+			problems = append(problems, fmt.Sprintf("%s in %s", p, raw))
+		}
+		for _, a := range z.Token().Attr {
+			if a.Key == "id" {
+				if ids[a.Val] == 0 {
+					order = append(order, a.Val)
+				}
+				ids[a.Val]++
+			}
+		}
+	}
+	for _, id := range order {
+		if n := ids[id]; n > 1 {
+			problems = append(problems, fmt.Sprintf("id %q is used by %d elements", id, n))
+		}
+	}
+	for _, id := range want {
+		if ids[id] == 0 {
+			problems = append(problems, fmt.Sprintf("no element has id %q", id))
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// checkQuotes returns a description of each badly quoted attribute value in
+// raw, the text of a start tag. A value in quotes must end with the same
+// quote, and can't contain a "<", which it would if the closing quote were
+// missing. A value without quotes can't contain quotes, "=", "<", ">" or "`".
+// Nor can an attribute name contain ",", quotes or "<", as it does after a
+// stray comma between attributes.
+func checkQuotes(raw string) (problems []string) {
+	s := strings.TrimSuffix(strings.TrimPrefix(raw, "<"), ">")
+	// Skip the tag name
+	i := strings.IndexAny(s, " \t\n\f\r/")
+	if i < 0 {
+		return
+	}
+	s = s[i:]
+	for {
+		s = strings.TrimLeft(s, " \t\n\f\r/")
+		if s == "" {
+			return
+		}
+		// The attribute name runs to a space or "="
+		i = strings.IndexAny(s, " \t\n\f\r/=")
+		if i < 0 {
+			return
+		}
+		name := s[:i]
+		if strings.ContainsAny(name, ",\"'<") {
+			problems = append(problems, fmt.Sprintf("attribute name %s is not valid, is a separator misplaced?", name))
+		}
+		s = strings.TrimLeft(s[i:], " \t\n\f\r")
+		if !strings.HasPrefix(s, "=") {
+			continue // no value
+		}
+		s = strings.TrimLeft(s[1:], " \t\n\f\r")
+		if s == "" {
+			problems = append(problems, fmt.Sprintf("attribute %s has no value", name))
+			return
+		}
+		switch q := s[0]; q {
+		case '"', '\'':
+			end := strings.IndexByte(s[1:], q)
+			if end < 0 {
+				problems = append(problems, fmt.Sprintf("value of attribute %s has no closing %c", name, q))
+				return
+			}
+			if strings.Contains(s[1:end+1], "<") {
+				problems = append(problems, fmt.Sprintf("value of attribute %s contains \"<\", is a %c missing?", name, q))
+			}
+			s = s[end+2:]
+		default:
+			end := strings.IndexAny(s, " \t\n\f\r")
+			if end < 0 {
+				end = len(s)
+			}
+			if strings.ContainsAny(s[:end], "\"'=<>`") {
+				problems = append(problems, fmt.Sprintf("unquoted value of attribute %s is %s, is a quote missing?", name, s[:end]))
+			}
+			s = s[end:]
+		}
+	}
+}
+
+// updaterIDs returns the ids of the elements that the generated
+// UpdateParmReadouts writes to for the parameters in placed: each parameter's
+// name and, for a trend, its name with a "-trend" suffix.
+func updaterIDs(placed []Placement) (ids []string) {
+	for _, p := range placed {
+		ids = append(ids, p.Name)
+		if p.Widget == Trend {
+			ids = append(ids, p.Name+"-trend")
+		}
+	}
+	return
+}
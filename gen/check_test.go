@@ -0,0 +1,48 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckHTML(t *testing.T) {
+	tests := []struct {
+		page  string
+		want  []string
+		probs []string // expected in the error, none if empty
+	}{
+		{`<div id="A" class="X"><td class=Y>a</td></div>`, []string{"A"}, nil},
+		{`<input type="checkbox" checked id='B'>`, []string{"B"}, nil},
+		{`<td class=STATUS">GET:</td>`, nil, []string{`unquoted value of attribute class is STATUS"`}},
+		{`<td class="STATUS>GET:</td><td id="A">`, nil, []string{`value of attribute class contains "<"`}},
+		{`<td class="STATUS>GET:</td>`, nil, []string{`value of attribute class has no closing "`}},
+		{`<meta name="description", content="PGC Remote Interface">`, nil, []string{`attribute name , is not valid`}},
+		{`<p id="GetMsg"></p><span id="GetMsg">`, nil, []string{`id "GetMsg" is used by 2 elements`}},
+		{`<p id="Alpha"></p>`, []string{"Alpha", "Alpha-trend"}, []string{`no element has id "Alpha-trend"`}},
+	}
+	for _, test := range tests {
+		err := CheckHTML([]byte(test.page), test.want)
+		if len(test.probs) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.page, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", test.page)
+			continue
+		}
+		for _, p := range test.probs {
+			if !strings.Contains(err.Error(), p) {
+				t.Errorf("%s: expected error to contain %q, got %v", test.page, p, err)
+			}
+		}
+	}
+}
+
+func TestUpdaterIDs(t *testing.T) {
+	placed := []Placement{{Meta{Name: "Alpha"}, Trend}, {Meta{Name: "On"}, LED}}
+	if got := strings.Join(updaterIDs(placed), " "); got != "Alpha Alpha-trend On" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,195 @@
+package gen
+
+// IndexCSS defines CSS styling for index.html, served as style.css. In a real
+// app, stylesheets may become large and intricate, hence the choice to put the
+// generation in a separate file. Note that the text content here is straight
+// CSS with no need for special quoting. Colours come from custom properties
+// set by the rules for each of themes, so the page depends on no external
+// stylesheet.
+func IndexCSS(themes []Theme) string {
+	return "/* Code generated by wasmskel/gen. DO NOT EDIT. */\n\n\t/* Themes */\n" + themeCSS(themes) + `
+	/* Base styling */
+	html {
+		background: var(--background);
+		color: var(--text);
+	}
+	body {
+		font-family: Verdana, sans-serif;
+		font-size: 15px;
+		line-height: 1.5;
+		margin: 0 2vh;
+	}
+	h3, h4 {
+		font-weight: 400;
+		margin: 10px 0;
+	}
+	table {
+		border-collapse: collapse;
+	}
+	input, select, button {
+		font: inherit;
+		color: var(--text);
+		background: var(--input);
+		border: 1px solid var(--border);
+	}
+	:focus-visible {
+		outline: 2px solid var(--accent);
+		outline-offset: 2px;
+	}
+	th.PARM, th.STATUS {
+		font-weight: 400;
+		text-align: left;
+	}
+	thead th.PARM {
+		color: var(--muted);
+	}
+	p.HELP {
+		font-size: small;
+		color: var(--muted);
+	}
+	div.THEME {
+		float: right;
+		font-size: small;
+	}
+
+	/* Link status styling */
+	div.LINK {
+		font-size: small;
+	}
+	span.LINK.connected {
+		color: var(--connected);
+	}
+	span.LINK.degraded {
+		color: var(--degraded);
+	}
+	span.LINK.offline {
+		color: var(--offline);
+		font-weight: bold;
+	}
+
+	/* Offline banner, shown while the values aren't live */
+	div.OFFLINE {
+		display: none;
+	}
+	div.OFFLINE.shown {
+		display: block;
+		margin: 6px 0;
+		padding: 4px 8px;
+		border: 2px solid var(--offline);
+		color: var(--offline);
+		font-weight: bold;
+	}
+
+	/* Status class styling */
+	table.STATUS {
+		margin-left: 5vh;
+		font-size: small;
+	}
+	td.STATUS {
+		margin-left: 1vh;
+	}
+	td.STATUS.FAIL {
+		color: var(--fail);
+	}
+
+	/* Layout styling */
+	nav.NAV {
+		margin: 10px 0;
+		border-bottom: 1px solid var(--border);
+	}
+	a.NAV {
+		display: inline-block;
+		padding: 4px 12px;
+		color: inherit;
+		text-decoration: none;
+	}
+	a.NAV.active {
+		font-weight: bold;
+		border-bottom: 2px solid var(--accent);
+	}
+	div.VIEW.inactive {
+		display: none;
+	}
+	div.COLUMNS {
+		display: flex;
+		flex-wrap: wrap;
+		align-items: flex-start;
+		gap: 2vh;
+	}
+	div.PANEL {
+		border: 1px solid var(--border);
+		padding: 0 1vh 1vh 0;
+		margin-bottom: 2vh;
+	}
+	div.PANEL h4 {
+		margin-left: 1vh;
+	}
+
+	table.HISTORY {
+		font-size: small;
+		text-align: right;
+	}
+	table.HISTORY th, table.HISTORY td {
+		padding: 0 1vh;
+	}
+	ul.LOG {
+		font-size: small;
+		list-style: none;
+		padding-left: 1vh;
+	}
+
+	/* Widget styling */
+	div.GAUGE svg {
+		width: 8em;
+		display: block;
+	}
+	div.BAR svg {
+		width: 10em;
+		height: 1.4em;
+		display: block;
+	}
+	div.LED svg {
+		width: 1.2em;
+		height: 1.2em;
+		display: block;
+	}
+	svg .track {
+		fill: none;
+		stroke: var(--border);
+	}
+	svg .fill {
+		fill: var(--accent);
+		stroke: var(--accent);
+	}
+	svg .lit {
+		fill: var(--connected);
+	}
+	svg text {
+		fill: currentColor;
+		font-family: inherit;
+	}
+	div.TREND svg {
+		width: 10em;
+		height: 2em;
+		display: block;
+	}
+
+	/* Parameters class styling */
+	table.PARM {
+		margin-left: 5vh;
+		font-size: small;
+	}
+	td.PARM {
+		margin-left: 1vh;
+	}
+	button.PARM {
+		font-style: italic;
+	}
+	.PARM.STALE .PARM[id] {
+		color: var(--muted);
+	}
+	input.PARM[type="number"] {
+		width: 8em;
+	}
+	`
+}
@@ -0,0 +1,146 @@
+// Package gen generates the source files and web assets that depend on the
+// parameter schema: the shared State struct, the wasm client's readout
+// updater, the server's dispatcher and the index page. It has no dependency on
+// mage so it can be driven from a magefile, from go:generate via
+// cmd/wasmskelgen, or from tests.
+package gen
+
+import (
+	"fmt"
+	"path"
+)
+
+// Config tells Generate what to generate and where to put it.
+type Config struct {
+	Parms        []Meta   // the parameter schema
+	CommonDir    string   // output directory for state_g.go
+	ServerDir    string   // output directory for dispatch_g.go
+	WasmDir      string   // output directory for updater_g.go
+	AssetsDir    string   // output directory for index.html
+	CommonImport string   // import path of the package in CommonDir
+	Worker       bool     // run the wasm client in a Web Worker
+	Themes       []Theme  // themes the page offers, DefaultThemes if nil
+	Layout       Layout   // arrangement of the page, DefaultLayout if empty
+	Locales      []Locale // languages the page offers, DefaultLocales if nil
+}
+
+// File is a generated file: where it goes and what it contains.
+type File struct {
+	Path string
+	Data []byte
+}
+
+// Files returns the files described by cfg without writing anything. The
+// schema is validated first, and Go sources are formatted and known to parse.
+func Files(cfg Config) (files []File, err error) {
+	if cfg.CommonImport == "" {
+		err = fmt.Errorf("no import path given for the common package")
+		return
+	}
+	err = Validate(cfg.Parms)
+	if err != nil {
+		return
+	}
+	err = ValidateLayout(cfg.layout(), cfg.Parms)
+	if err != nil {
+		return
+	}
+	err = validateThemes(cfg.themes())
+	if err != nil {
+		return
+	}
+	err = validateLocales(cfg)
+	if err != nil {
+		return
+	}
+	for _, g := range []func(Config) (File, error){
+		genState,         // the common state struct
+		genParms,         // and the checks on its settable fields
+		genStateJSON,     // and its JSON encoding
+		genStateJSONTest, // and the tests and benchmarks for that
+		genIndexPage,     // the web page
+		genStyleSheet,    // its styles
+		genThemeScript,   // and scripts
+		genAppScript,
+		genI18nScript,
+		genManifest,       // what the browser needs to install the app
+		genIcon,           // and show it
+		genServiceWorker,  // and to start it offline
+		genUpdater,        // the wasm client's updater function
+		genPages,          // and what it needs to know about the views
+		genLocales,        // and about the languages
+		genDispatcher,     // the server's dispatcher function
+		genDispatcherTest, // and its test
+		genPolicy,         // and the page's Content-Security-Policy
+	} {
+		var f File
+		f, err = g(cfg)
+		if err != nil {
+			return
+		}
+		files = append(files, f)
+	}
+	if cfg.Worker {
+		files = append(files, genWorkerScript(cfg))
+	}
+	return
+}
+
+// Generate writes all the generated files described by cfg. Nothing is written
+// unless every file generates cleanly, and each file is replaced atomically.
+func Generate(cfg Config) (err error) {
+	files, err := Files(cfg)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		err = writeFile(f)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// tmplData is the value passed to the code templates.
+type tmplData struct {
+	Parms        []Meta
+	Placed       []Placement // parameters shown on the page
+	CommonImport string      // import path of the common package
+	CommonName   string      // package name of the common package
+}
+
+// data returns the template data for cfg. The common package name is the last
+// element of cfg.CommonImport.
+func (cfg Config) data() tmplData {
+	return tmplData{
+		Parms:        cfg.Parms,
+		Placed:       cfg.layout().placements(cfg.Parms),
+		CommonImport: cfg.CommonImport,
+		CommonName:   path.Base(cfg.CommonImport),
+	}
+}
+
+// themes returns the themes the page offers.
+func (cfg Config) themes() []Theme {
+	if cfg.Themes == nil {
+		return DefaultThemes
+	}
+	return cfg.Themes
+}
+
+// locales returns the languages the page offers.
+func (cfg Config) locales() []Locale {
+	if cfg.Locales == nil {
+		return DefaultLocales
+	}
+	return cfg.Locales
+}
+
+// layout returns the arrangement of the page.
+func (cfg Config) layout() Layout {
+	if len(cfg.Layout.Tabs) == 0 {
+		return DefaultLayout(cfg.Parms)
+	}
+	return cfg.Layout
+}
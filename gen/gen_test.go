@@ -0,0 +1,352 @@
+package gen
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+var testParms = []Meta{
+	{Name: "Alpha", Type: Float},
+	{Name: "Gamma", Type: Float, Settable: true, Min: -1, Max: 1, Slider: true},
+	{Name: "Mode", Type: Enum, Settable: true, Options: []string{"On", "Off"}},
+}
+
+// testConfig returns a Config that writes all files into a temporary dir.
+func testConfig(t *testing.T) Config {
+	dir := t.TempDir()
+	return Config{
+		Parms:        testParms,
+		CommonDir:    dir,
+		ServerDir:    dir,
+		WasmDir:      dir,
+		AssetsDir:    dir,
+		CommonImport: "example.com/app/shared",
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	cfg := testConfig(t)
+	err := Generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string][]string{
+		"state_g.go":           {"package shared", "Alpha float64", "Gamma float64"},
+		"updater_g.go":         {"SP.Alpha", "SP.Gamma"},
+		"dispatch_g.go":        {`"example.com/app/shared"`, "shared.UnsettableErr(varName)", "State.Apply(jsonName, rawval)"},
+		"index.html":           {`id="Alpha"`, `<th scope="row" class="STATUS" data-msg="get">GET:`, `<td class="STATUS" id="SetMsg" role="status" aria-live="polite" aria-atomic="true">`, `id="SetMsg-time"`, `<p id="KeyHelp" class="HELP" data-msg="keyHelp">`, `<button class="PARM" data-parm="Gamma" aria-label="Set Gamma" data-msg="setButton">Set`, `<label for="Gamma-input">`, `<span data-parm-label="Gamma">Gamma`, `<th scope="col" class="PARM" data-msg="valueColumn">Value`, `<script src="/i18n.js">`, `<select id="LocaleSelect" class="THEME">`, `<input type="range" id="Gamma-input" class="PARM" aria-describedby="KeyHelp" min="-1" max="1" step="any">`, `<select id="Mode-input" class="PARM" aria-describedby="KeyHelp">`},
+		"parms_g.go":           {`err = UnsettableErr("Alpha")`, "p.Gamma = value.(float64)", "func checkGamma(v float64)", "if v < -1 || v > 1 {", `case "On", "Off":`},
+		"json_g.go":            {`var stateFields = []string{"Alpha", "Gamma", "Mode"}`, "appendFloat(b, s.Gamma)", `decodeStringField(value, &s.Mode, "Mode")`},
+		"json_g_test.go":       {"Gamma: float64(0),", `{"Mode":1}`},
+		"dispatch_g_test.go":   {`{"Alpha", false, "12.5", "",`, `{"Gamma", true, "0", "2",`, `{"Mode", true, "\"Off\"", "\"On|Off?\"",`},
+		"policy_g.go":          {`const contentSecurityPolicy = "default-src 'none'; script-src 'self' 'wasm-unsafe-eval';`, "worker-src 'self'; manifest-src 'self'"},
+		"app.js":               {`fetch("/app.wasm")`, `navigator.serviceWorker.register("/sw.js")`},
+		"sw.js":                {`const SHELL = ["/", "/style.css", "/theme.js", "/i18n.js", "/app.js", "/wasm_exec.js", "/app.wasm", "/manifest.webmanifest", "/icon.svg"];`},
+		"manifest.webmanifest": {`"start_url": "/"`, `"display": "standalone"`, `"src": "/icon.svg"`},
+		"theme.js":             {`getElementById("ThemeSelect")`},
+		"i18n.js":              {`var localeTags = ["en", "de", "fr"];`, `"setButton": "Setzen"`, `"Parameter Values": "Parameterwerte"`},
+		"locales_g.go":         {`const defaultLocale = "en"`, `"de": {decimal: ",", group: ".", date: "02.01.2006"`, `"verbunden",`},
+		"style.css":            {"--accent:"},
+	}
+	for fname, wants := range expect {
+		b, err := ioutil.ReadFile(path.Join(cfg.CommonDir, fname))
+		if err != nil {
+			t.Errorf("%v", err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s: expected to find %q", fname, want)
+			}
+		}
+	}
+}
+
+func TestGenerateNoImport(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.CommonImport = ""
+	if err := Generate(cfg); err == nil {
+		t.Errorf("expected an error when CommonImport is empty")
+	}
+}
+
+func TestSourceNamesParameter(t *testing.T) {
+	data := tmplData{Parms: []Meta{{Name: "Alpha", Type: Float}, {Name: "Bad Name", Type: Float}}}
+	_, err := source("test", "package x\ntype S struct {\n{{range .Parms}}{{.Name}} {{.Type}}\n{{end}}}\n", data)
+	if err == nil {
+		t.Fatal("expected a syntax error")
+	}
+	if !strings.Contains(err.Error(), "parameter Bad Name:") {
+		t.Errorf("expected error to name the parameter, got %v", err)
+	}
+	if strings.Contains(err.Error(), "Alpha") {
+		t.Errorf("expected error not to blame Alpha, got %v", err)
+	}
+}
+
+func TestGenerateWritesNothingOnError(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Parms = append(cfg.Parms, Meta{Name: "Bad Name", Type: Float})
+	if err := Generate(cfg); err == nil {
+		t.Fatal("expected an error")
+	}
+	entries, err := ioutil.ReadDir(cfg.CommonDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no files to be written, found %d", len(entries))
+	}
+}
+
+func TestGenerateWorker(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Worker = true
+	err := Generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := ioutil.ReadFile(path.Join(cfg.AssetsDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(page), "wasm_exec.js") {
+		t.Errorf("expected the page not to load wasm_exec.js")
+	}
+	app, err := ioutil.ReadFile(path.Join(cfg.AssetsDir, "app.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(app), `new Worker("/worker.js" + location.search)`) {
+		t.Errorf("expected app.js to start a worker")
+	}
+	sw, err := ioutil.ReadFile(path.Join(cfg.AssetsDir, "sw.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sw), `"/icon.svg", "/worker.js"];`) {
+		t.Errorf("expected the service worker to keep worker.js for offline use")
+	}
+	if _, err := ioutil.ReadFile(path.Join(cfg.AssetsDir, "worker.js")); err != nil {
+		t.Errorf("expected worker.js: %v", err)
+	}
+}
+
+func TestGenerateThemes(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Themes = []Theme{{Name: "paper", Scheme: "light", Text: "#111111"}, {Name: "night", Scheme: "dark", Text: "#eeeeee"}}
+	err := Generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string][]string{
+		"style.css":  {`:root[data-theme="night"]`, "@media (prefers-color-scheme: dark)"},
+		"theme.js":   {`var themeNames = ["paper", "night"];`},
+		"index.html": {`<option value="paper" data-title="paper">paper`},
+	}
+	for fname, wants := range expect {
+		b, err := ioutil.ReadFile(path.Join(cfg.AssetsDir, fname))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s: expected to find %q", fname, want)
+			}
+		}
+		if strings.Contains(string(b), "http") {
+			t.Errorf("%s: expected nothing to be loaded from other sites", fname)
+		}
+	}
+	for _, bad := range [][]Theme{{}, {{Name: "Bad Name"}}, {{Name: "a"}, {Name: "a"}}} {
+		cfg.Themes = bad
+		if err := Generate(cfg); err == nil {
+			t.Errorf("expected themes %v to be rejected", bad)
+		}
+	}
+}
+
+// TestPageIsSelfContained checks that index.html has no inline scripts,
+// styles or event handlers, which the Content-Security-Policy would block.
+func TestPageIsSelfContained(t *testing.T) {
+	for _, worker := range []bool{false, true} {
+		cfg := testConfig(t)
+		cfg.Worker = worker
+		if err := Generate(cfg); err != nil {
+			t.Fatal(err)
+		}
+		page, err := ioutil.ReadFile(path.Join(cfg.AssetsDir, "index.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, bad := range []string{"<script>", "<style", " style=", " onclick=", " onchange=", " oninput=", "javascript:"} {
+			if strings.Contains(string(page), bad) {
+				t.Errorf("worker %v: expected page not to contain %q", worker, bad)
+			}
+		}
+	}
+}
+
+func TestGenerateLayout(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Parms = append(cfg.Parms, Meta{Name: "On", Type: Bool})
+	cfg.Layout = Layout{Tabs: []Tab{
+		{Title: "Main", Columns: []Column{
+			{Panels: []Panel{{Title: "Readings", Items: []Item{{Parm: "Alpha", Widget: Trend}, {Parm: "On", Widget: LED}}}}},
+			{Panels: []Panel{{Title: "Controls", Items: []Item{{Parm: "Gamma", Widget: Gauge}}}}},
+		}},
+		{Title: "More stuff!", Columns: []Column{{Panels: []Panel{{Title: "Other", Items: []Item{{Parm: "Mode"}}}}}}},
+	}}
+	err := Generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string][]string{
+		"index.html": {
+			`<nav class="NAV" aria-label="Views" data-msg-label="views">`,
+			`<a id="Nav-main" class="NAV active" href="#/main" aria-current="page" data-title="Main">Main`,
+			`<a id="Nav-more-stuff" class="NAV" href="#/more-stuff" aria-current="false" data-title="More stuff!">More stuff`,
+			`<div id="View-more-stuff" class="VIEW inactive">`,
+			`<a id="Nav-logs" class="NAV" href="#/logs" aria-current="false" data-msg="logs">Logs`,
+			`<th scope="col" data-msg="time">Time`,
+			`<tbody id="HistoryRows" class="PARM">`,
+			`<h4 data-title="Controls">Controls`,
+			`<div id="Gamma" class="PARM GAUGE">`,
+			`<div id="On" class="PARM LED">`,
+			`<div id="Alpha-trend" class="TREND" aria-hidden="true">`,
+			`<span data-parm-label="On">On`,
+		},
+		"pages_g.go": {
+			`var routes = []string{"main", "more-stuff", "history", "logs"}`,
+			`formatFloat(s.Alpha, 2),`,
+			`s.Mode,`,
+		},
+		"updater_g.go": {
+			`trends.add("Alpha", float64(SP.Alpha))`,
+			`setElementAttributeById("Alpha-trend", "innerHTML", trends.svg("Alpha", 0, 0))`,
+			`setElementAttributeById("On", "innerHTML", lampSVG(SP.On))`,
+			`setElementAttributeById("Gamma", "innerHTML", gaugeSVG(float64(SP.Gamma), -1, 1, formatFloat(SP.Gamma, 2)))`,
+			`setElementAttributeById("Mode", "textContent", SP.Mode)`,
+		},
+	}
+	for fname, wants := range expect {
+		b, err := ioutil.ReadFile(path.Join(cfg.CommonDir, fname))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s: expected to find %q", fname, want)
+			}
+		}
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	parms := append(testParms, Meta{Name: "On", Type: Bool})
+	tests := []struct {
+		items []Item
+		want  string // in the error, or "" for none
+	}{
+		{[]Item{{Parm: "Alpha"}, {Parm: "On", Widget: LED}, {Parm: "Gamma", Widget: Bar}}, ""},
+		{[]Item{{Parm: "Nope"}}, "no such parameter"},
+		{[]Item{{Parm: "Alpha"}, {Parm: "Alpha"}}, "already placed at tab 1 column 1 panel 1 item 1"},
+		{[]Item{{Parm: "Alpha", Widget: Gauge}}, `widget "gauge" requires a numeric type with Min < Max`},
+		{[]Item{{Parm: "Mode", Widget: Trend}}, `widget "trend" requires a numeric type`},
+		{[]Item{{Parm: "Mode", Widget: LED}}, `widget "led" requires a numeric or bool type`},
+		{[]Item{{Parm: "Alpha", Widget: "dial"}}, `unknown widget "dial"`},
+	}
+	for _, tc := range tests {
+		layout := Layout{Tabs: []Tab{{Title: "Main", Columns: []Column{{Panels: []Panel{{Items: tc.items}}}}}}}
+		err := ValidateLayout(layout, parms)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%v: unexpected error %v", tc.items, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("%v: expected error containing %q, got %v", tc.items, tc.want, err)
+		}
+	}
+	for _, tc := range []struct {
+		tabs []Tab
+		want string
+	}{
+		{[]Tab{{Title: "Main"}, {Title: "main"}}, `tab 2 "main": route "main" is already used by tab 1`},
+		{[]Tab{{Title: "Logs"}}, `route "logs" is reserved`},
+		{[]Tab{{Title: "?"}}, `route "" must be lower case`},
+		{[]Tab{{Title: "Main", Route: "Main"}}, `route "Main" must be lower case`},
+	} {
+		err := ValidateLayout(Layout{Tabs: tc.tabs}, parms)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.tabs, tc.want, err)
+		}
+	}
+}
+
+func TestGenerateLocales(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Parms = []Meta{
+		{Name: "Alpha", Type: Float, Labels: map[string]string{"es": "Alfa"}},
+		{Name: "Count", Type: Int},
+	}
+	cfg.Locales = []Locale{
+		{Tag: "es", Label: "Español", Decimal: ",", Group: ".", Messages: map[string]string{"setButton": "Fijar"}},
+		{Tag: "en-GB", Label: "English", Decimal: "."},
+	}
+	err := Generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string][]string{
+		"i18n.js": {
+			`var localeTags = ["es", "en-GB"];`,
+			`"setButton": "Fijar"`,
+			`"Alpha": "Alfa"`,
+			`"valueColumn": "Value"`, // English for keys the locale leaves out
+		},
+		"index.html":   {`<option value="es" lang="es">Español`, `<span data-parm-label="Alpha">Alpha`},
+		"locales_g.go": {`const defaultLocale = "es"`, `"en-GB": {decimal: ".", group: "", date: "2006-01-02"`},
+		"pages_g.go":   {`formatFloat(s.Alpha, 2),`, `formatInt(s.Count),`},
+	}
+	for fname, wants := range expect {
+		b, err := ioutil.ReadFile(path.Join(cfg.CommonDir, fname))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("%s: expected to find %q", fname, want)
+			}
+		}
+	}
+	for _, tc := range []struct {
+		locales []Locale
+		parms   []Meta
+		want    string
+	}{
+		{[]Locale{}, nil, "no locales defined"},
+		{[]Locale{{Tag: "EN", Decimal: "."}}, nil, `locale 1 "EN": tag must be a language tag`},
+		{[]Locale{{Tag: "en", Decimal: "."}, {Tag: "en", Decimal: "."}}, nil, `locale 2 "en": duplicate tag`},
+		{[]Locale{{Tag: "en", Decimal: ".", Group: "."}}, nil, "needs a decimal separator unlike the group separator"},
+		{[]Locale{{Tag: "en", Decimal: ".", Messages: map[string]string{"nope": "x"}}}, nil, `unknown message key "nope"`},
+		{[]Locale{{Tag: "en", Decimal: "."}}, []Meta{{Name: "Alpha", Type: Float, Labels: map[string]string{"de": "A"}}}, `parameter "Alpha": label for unknown locale "de"`},
+	} {
+		cfg.Locales = tc.locales
+		if tc.parms != nil {
+			cfg.Parms = tc.parms
+		}
+		err := Generate(cfg)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.locales, tc.want, err)
+		}
+	}
+	// Every problem is reported, not just the first
+	cfg.Locales = []Locale{{Tag: "EN", Decimal: "."}, {Tag: "de", Decimal: ",", Group: ","}}
+	err = Generate(cfg)
+	for _, want := range []string{`locale 1 "EN": tag must be`, `locale 2 "de": needs a decimal separator`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
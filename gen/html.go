@@ -0,0 +1,224 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path"
+	"strings"
+
+	// goht is usually dot imported, but its Meta tag function would collide
+	// with our Meta type.
+	h "github.com/Michael-F-Ellis/goht"
+)
+
+// genIndexPage generates index.html in cfg.AssetsDir
+func genIndexPage(cfg Config) (f File, err error) {
+	var buf bytes.Buffer
+	// <head>
+	head := h.Head("",
+		h.Title(``, appTitle),
+		h.Meta(`name="viewport" content="width=device-width, initial-scale=1"`),
+		h.Meta(`name="description" content="PGC Remote Interface"`),
+		// Styles and scripts are in separate files so that the page can be
+		// served with a strict Content-Security-Policy. See assets.go.
+		h.Meta(fmt.Sprintf(`name="theme-color" content="%s"`, cfg.themes()[0].Accent)),
+		h.Link(`rel="manifest" href="/manifest.webmanifest"`),
+		h.Link(`rel="icon" href="/icon.svg" type="image/svg+xml"`),
+		h.Link(`rel="stylesheet" href="/style.css"`),
+		h.Script(`src="/theme.js"`), // not deferred, to apply the theme before rendering
+		h.Script(`src="/i18n.js"`),  // or to set the language before the client starts
+		LoaderScript(cfg.Worker),
+	)
+
+	// Put the head and body together
+	page := h.Html(`lang="en"`,
+		h.Null("\n<!-- Code generated by wasmskel/gen. DO NOT EDIT -->"),
+		head,
+		IndexBody(Pages(cfg), cfg.themes(), cfg.locales()),
+	)
+
+	// Render the html
+	err = h.Render(page, &buf, 0)
+	if err != nil {
+		return
+	}
+	// Catch malformed markup, and elements the wasm client can't find
+	err = CheckHTML(buf.Bytes(), updaterIDs(cfg.data().Placed))
+	if err != nil {
+		return
+	}
+	f.Path = path.Join(cfg.AssetsDir, "index.html")
+	f.Data = buf.Bytes()
+	return
+}
+
+// LoaderScript returns the script elements that start the wasm client, either
+// on the page's main thread or, if worker is true, in a Web Worker. They are
+// deferred so that the page is complete when the client wires its controls.
+func LoaderScript(worker bool) (scrpt *h.HtmlTree) {
+	if worker {
+		scrpt = h.Script(`src="/app.js" defer`)
+		return
+	}
+	scrpt = h.Null(
+		// Load the Go wasm interface library
+		h.Script(`src="/wasm_exec.js" charset=UTF-8 defer`),
+		h.Script(`src="/app.js" defer`),
+	)
+	return
+}
+
+// StatusTable returns a div containing a table element with 2 rows with 3 cells
+// in each:
+// | Get   | (latest status) | (its latency) |
+// | Set   | (latest status) | (its latency) |
+// The status cells are live regions, so screen readers announce the outcomes
+// of requests. The latencies, which change with every request, are not.
+func StatusTable() (tbl *h.HtmlTree) {
+	var rows []interface{}
+	for _, r := range []struct{ key, id string }{{"get", "GetMsg"}, {"set", "SetMsg"}} {
+		rows = append(rows, h.Tr(`class="STATUS"`,
+			h.Th(fmt.Sprintf(`scope="row" class="STATUS" data-msg="%s"`, r.key), msg(r.key)),
+			h.Td(fmt.Sprintf(`class="STATUS" id="%s" role="status" aria-live="polite" aria-atomic="true"`, r.id)),
+			h.Td(fmt.Sprintf(`class="STATUS" id="%s-time"`, r.id))))
+	}
+	tbl = h.Div(``, h.H4(`data-msg="statusTitle"`, msg("statusTitle")), h.Table(`class="STATUS"`, rows...))
+	return
+}
+
+// PanelDiv returns a div with the panel's title and a table with a header row
+// and a row for each of its items. Each row has 3 cells. For settable
+// parameters, the first cell contains an input control and a "Set" button.
+// For non-settable parameters it is empty. The second, a row header, contains
+// the parameter name, which labels the input control. The third holds the
+// widget that shows the latest value read from the server. i18n.js
+// translates the title, the headers and the parameter names.
+func PanelDiv(panel Panel, byName map[string]Meta) (div *h.HtmlTree) {
+	rows := []interface{}{h.Thead(``, h.Tr(`class="PARM"`,
+		h.Th(`scope="col" class="PARM" data-msg="setColumn"`, msg("setColumn")),
+		h.Th(`scope="col" class="PARM" data-msg="parameterColumn"`, msg("parameterColumn")),
+		h.Th(`scope="col" class="PARM" data-msg="valueColumn"`, msg("valueColumn"))))}
+	for _, item := range panel.Items {
+		parm := byName[item.Parm]
+		var ctl, label *h.HtmlTree
+		switch parm.Settable {
+		case false:
+			ctl = h.Td(`class="PARM"`) // empty cell
+			label = h.Th(`scope="row" class="PARM"`, ParmName(parm))
+		case true:
+			// The wasm client, or in Worker mode the page script, makes
+			// the button call SetParm with its data-parm.
+			button := h.Button(fmt.Sprintf(`class="PARM" data-parm="%s" aria-label="Set %s" data-msg="setButton"`, parm.Name, parm.Name), msg("setButton"))
+			ctl = h.Td(`class="PARM"`, ParmInput(parm), button)
+			label = h.Th(`scope="row" class="PARM"`, h.Label(fmt.Sprintf(`for="%s-input"`, parm.Name), ParmName(parm)))
+		}
+		rows = append(rows, h.Tr(`class="PARM"`, ctl, label, WidgetCell(Placement{parm, item.widget()})))
+	}
+	title := html.EscapeString(panel.Title)
+	div = h.Div(`class="PANEL"`, h.H4(fmt.Sprintf(`data-title="%s"`, title), title), h.Table(`class="PARM"`, rows...))
+	return
+}
+
+// ParmName returns a span with the name of parm, which i18n.js replaces with
+// its display name in the chosen locale.
+func ParmName(parm Meta) (span *h.HtmlTree) {
+	span = h.Span(fmt.Sprintf(`data-parm-label="%s"`, parm.Name), parm.Name)
+	return
+}
+
+// WidgetCell returns the table cell that shows the value of p. The element
+// whose id is the parameter name is a text readout or, for a gauge, bar or
+// LED, a container that the wasm client draws the widget in. A trend has a
+// readout and a chart whose id has a "-trend" suffix. The generated
+// UpdateParmReadouts keeps them up to date.
+func WidgetCell(p Placement) (td *h.HtmlTree) {
+	switch p.Widget {
+	case Gauge, Bar, LED:
+		class := strings.ToUpper(p.Widget)
+		td = h.Td(`class="PARM"`, h.Div(fmt.Sprintf(`id="%s" class="PARM %s"`, p.Name, class)))
+	case Trend:
+		readout := h.Span(fmt.Sprintf(`id="%s" class="PARM"`, p.Name))
+		// The chart repeats the readout for sighted users
+		td = h.Td(`class="PARM"`, readout, h.Div(fmt.Sprintf(`id="%s-trend" class="TREND" aria-hidden="true"`, p.Name)))
+	default:
+		td = h.Td(fmt.Sprintf(`id="%s" class="PARM"`, p.Name))
+	}
+	return
+}
+
+// ParmInput returns the input control for a settable parameter: a number
+// input or slider for numeric types, a checkbox for Bool and a dropdown for
+// Enum. The control's id is the parameter name with an "-input" suffix. The
+// wasm client's SetParm function reads it. The control is described by
+// KeyHelp, which gives its keyboard shortcuts.
+func ParmInput(parm Meta) (ctl *h.HtmlTree) {
+	id := parm.Name + "-input"
+	switch parm.Type {
+	case Bool:
+		ctl = h.Input(fmt.Sprintf(`type="checkbox" id="%s" class="PARM" aria-describedby="KeyHelp"`, id))
+	case Enum:
+		var opts []interface{}
+		for _, opt := range parm.Options {
+			opts = append(opts, h.Option(fmt.Sprintf(`value="%s"`, html.EscapeString(opt)), html.EscapeString(opt)))
+		}
+		ctl = h.Select(fmt.Sprintf(`id="%s" class="PARM" aria-describedby="KeyHelp"`, id), opts...)
+	default:
+		attrs := fmt.Sprintf(`type="number" id="%s" class="PARM" aria-describedby="KeyHelp"`, id)
+		if parm.Slider {
+			attrs = fmt.Sprintf(`type="range" id="%s" class="PARM" aria-describedby="KeyHelp"`, id)
+		}
+		if parm.Bounded() {
+			attrs += fmt.Sprintf(` min="%v" max="%v"`, parm.Min, parm.Max)
+		}
+		switch {
+		case parm.Step > 0:
+			attrs += fmt.Sprintf(` step="%v"`, parm.Step)
+		case parm.Type == Float:
+			attrs += ` step="any"` // browsers default to whole numbers
+		}
+		ctl = h.Input(attrs)
+	}
+	return
+}
+
+// LinkStatus returns a div that the wasm client keeps up to date with the
+// state of its connection to the server: connected, degraded or offline.
+func LinkStatus() (div *h.HtmlTree) {
+	div = h.Div(`class="LINK"`,
+		h.Span(`data-msg="link"`, msg("link")),
+		h.Span(`id="LinkStatus" class="LINK" role="status"`, h.Span(`data-msg="connecting"`, msg("connecting"))))
+	return
+}
+
+// OfflineBanner returns a div that the wasm client shows while the values on
+// the page aren't live: when the server can't be reached, and when the client
+// has started offline with the last values it saved.
+func OfflineBanner() (div *h.HtmlTree) {
+	div = h.Div(`id="OfflineBanner" class="OFFLINE" role="status"`)
+	return
+}
+
+// KeyHelp returns a paragraph describing the keyboard shortcuts of the
+// parameters' input controls, which the wasm client, or in Worker mode the
+// page script, provides.
+func KeyHelp() (p *h.HtmlTree) {
+	p = h.P(`id="KeyHelp" class="HELP" data-msg="keyHelp"`, msg("keyHelp"))
+	return
+}
+
+// IndexBody returns the body element for this page. It holds every view of
+// the app, of which the wasm client shows one at a time.
+func IndexBody(pages []Page, themes []Theme, locales []Locale) (body *h.HtmlTree) {
+	body = h.Body(``,
+		ThemePicker(themes),
+		LocalePicker(locales),
+		h.H3(`data-msg="heading"`, msg("heading")),
+		LinkStatus(),
+		OfflineBanner(),
+		StatusTable(),
+		KeyHelp(),
+		NavBar(pages),
+		ViewsDiv(pages))
+	return
+}
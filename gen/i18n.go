@@ -0,0 +1,442 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	h "github.com/Michael-F-Ellis/goht"
+)
+
+// The page is generated in English. Each element whose text depends on the
+// language names where its text comes from in a data attribute, and i18n.js
+// replaces the text when the user picks a locale:
+//
+//	data-msg="key"           the message key in Locale.Messages
+//	data-title="text"        a tab, panel or theme title, translated by its Labels
+//	data-parm-label="Name"   a parameter name, translated by Meta.Labels
+//
+// The wasm client formats numbers and writes its own messages, such as the
+// link state, in the locale i18n.js chose.
+
+// Locale is a language the page can be shown in.
+type Locale struct {
+	Tag      string            // BCP 47 language tag, e.g. "de" or "fr-CA"
+	Label    string            // shown in the locale picker, in the language itself
+	Decimal  string            // decimal separator
+	Group    string            // separator between groups of thousands, or ""
+	Date     string            // Go time layout for dates, "2006-01-02" if empty
+	Messages map[string]string // UI text by message key, English for missing keys
+}
+
+// englishMessages holds the text of every message key. The page is generated
+// with this text, and locales fall back on it for the keys they leave out.
+// Text in braces, e.g. {parm}, is replaced where the message is used.
+var englishMessages = map[string]string{
+	"title":           appTitle,
+	"heading":         "Go Web Assembly Skeleton App",
+	"theme":           "Theme: ",
+	"system":          "System",
+	"language":        "Language: ",
+	"link":            "Link: ",
+	"connecting":      "connecting",
+	"connected":       "connected",
+	"degraded":        "degraded",
+	"offline":         "offline",
+	"statusTitle":     "HTTP Status Messages",
+	"get":             "GET:",
+	"set":             "SET:",
+	"keyHelp":         "In a parameter's input, press Enter to set it or Escape to restore its current value.",
+	"views":           "Views",
+	"setColumn":       "Set",
+	"parameterColumn": "Parameter",
+	"valueColumn":     "Value",
+	"setButton":       "Set",
+	"setParm":         "Set {parm}",
+	"history":         "History",
+	"logs":            "Logs",
+	"time":            "Time",
+	"offlineNone":     "Offline: no values have been received from the server",
+	"offlineAt":       "Offline: showing the values received at {time}",
+	"offlineOn":       "Offline: showing the values received on {date} at {time}",
+	"on":              "on",
+	"off":             "off",
+	"true":            "true",
+	"false":           "false",
+}
+
+// clientMessages are the message keys the wasm client uses.
+var clientMessages = []string{
+	"connected", "degraded", "offline",
+	"offlineNone", "offlineAt", "offlineOn",
+	"on", "off", "true", "false",
+}
+
+// DefaultLocales are the locales the page offers unless Config.Locales says
+// otherwise. The first is used when the browser prefers none of them.
+var DefaultLocales = []Locale{
+	{Tag: "en", Label: "English", Decimal: ".", Group: ",", Date: "Jan 2"},
+	{Tag: "de", Label: "Deutsch", Decimal: ",", Group: ".", Date: "02.01.2006", Messages: map[string]string{
+		"title":           "Wasm-Skelett-Demo",
+		"heading":         "Go-WebAssembly-Skelett-App",
+		"theme":           "Design: ",
+		"language":        "Sprache: ",
+		"link":            "Verbindung: ",
+		"connecting":      "wird verbunden",
+		"connected":       "verbunden",
+		"degraded":        "gestört",
+		"offline":         "offline",
+		"statusTitle":     "HTTP-Statusmeldungen",
+		"keyHelp":         "Im Eingabefeld eines Parameters setzt Enter den Wert, Escape stellt den aktuellen Wert wieder her.",
+		"views":           "Ansichten",
+		"setColumn":       "Setzen",
+		"parameterColumn": "Parameter",
+		"valueColumn":     "Wert",
+		"setButton":       "Setzen",
+		"setParm":         "{parm} setzen",
+		"history":         "Verlauf",
+		"logs":            "Protokoll",
+		"time":            "Zeit",
+		"offlineNone":     "Offline: Vom Server wurden noch keine Werte empfangen",
+		"offlineAt":       "Offline: Werte von {time} Uhr",
+		"offlineOn":       "Offline: Werte vom {date} um {time} Uhr",
+		"on":              "an",
+		"off":             "aus",
+		"true":            "wahr",
+		"false":           "falsch",
+	}},
+	{Tag: "fr", Label: "Français", Decimal: ",", Group: "\u202f", Date: "02/01/2006", Messages: map[string]string{
+		"title":           "Démo squelette Wasm",
+		"heading":         "Application squelette Go WebAssembly",
+		"theme":           "Thème : ",
+		"system":          "Système",
+		"language":        "Langue : ",
+		"link":            "Liaison : ",
+		"connecting":      "connexion en cours",
+		"connected":       "connecté",
+		"degraded":        "dégradé",
+		"offline":         "hors ligne",
+		"statusTitle":     "Messages d’état HTTP",
+		"keyHelp":         "Dans le champ d’un paramètre, Entrée applique la valeur et Échap rétablit la valeur actuelle.",
+		"views":           "Vues",
+		"setColumn":       "Appliquer",
+		"parameterColumn": "Paramètre",
+		"valueColumn":     "Valeur",
+		"setButton":       "Appliquer",
+		"setParm":         "Appliquer {parm}",
+		"history":         "Historique",
+		"logs":            "Journal",
+		"time":            "Heure",
+		"offlineNone":     "Hors ligne : aucune valeur n’a encore été reçue du serveur",
+		"offlineAt":       "Hors ligne : valeurs reçues à {time}",
+		"offlineOn":       "Hors ligne : valeurs reçues le {date} à {time}",
+		"on":              "allumé",
+		"off":             "éteint",
+		"true":            "vrai",
+		"false":           "faux",
+	}},
+}
+
+// LoadLocales reads a JSON array of Locale objects from the file at fpath, e.g.
+//
+//	[{"Tag": "en", "Label": "English", "Decimal": ".", "Group": ","},
+//	 {"Tag": "de", "Label": "Deutsch", "Decimal": ",", "Group": ".",
+//	  "Messages": {"setButton": "Setzen"}}]
+func LoadLocales(fpath string) (locales []Locale, err error) {
+	jsn, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(jsn, &locales)
+	if err != nil {
+		err = fmt.Errorf("couldn't parse locales %s: %v", fpath, err)
+	}
+	return
+}
+
+// localeTagRE matches the language tags that are safe to use unquoted in
+// HTML and javascript.
+var localeTagRE = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// LocaleError lists every problem validateLocales found in a configuration.
+type LocaleError []string
+
+func (e LocaleError) Error() string {
+	return "invalid locales:\n\t" + strings.Join(e, "\n\t")
+}
+
+// validateLocales checks that there is at least one locale, that the tags are
+// unique and well formed, that every locale has a decimal separator unlike its
+// group separator and knows only the message keys in englishMessages, and
+// that the Labels of the parameters, tabs, panels and themes in cfg are for
+// those locales. The default layout and themes have labels for the default
+// locales, and may have more than the page uses. It returns a LocaleError
+// describing all the problems found, or nil.
+func validateLocales(cfg Config) error {
+	var problems LocaleError
+	report := func(i int, l Locale, format string, args ...interface{}) {
+		msg := fmt.Sprintf("locale %d %q: ", i+1, l.Tag) + fmt.Sprintf(format, args...)
+		problems = append(problems, msg)
+	}
+	locales := cfg.locales()
+	if len(locales) == 0 {
+		problems = append(problems, "no locales defined")
+	}
+	seen := map[string]bool{}
+	for i, l := range locales {
+		switch {
+		case !localeTagRE.MatchString(l.Tag):
+			report(i, l, "tag must be a language tag such as \"de\" or \"fr-CA\"")
+		case seen[l.Tag]:
+			report(i, l, "duplicate tag")
+		}
+		seen[l.Tag] = true
+		if l.Decimal == "" || l.Decimal == l.Group {
+			report(i, l, "needs a decimal separator unlike the group separator")
+		}
+		for _, key := range sortedKeys(l.Messages) {
+			if _, ok := englishMessages[key]; !ok {
+				report(i, l, "unknown message key %q", key)
+			}
+		}
+	}
+	checkLabels := func(what string, labels map[string]string) {
+		for _, tag := range sortedKeys(labels) {
+			if !seen[tag] {
+				problems = append(problems, fmt.Sprintf("%s: label for unknown locale %q", what, tag))
+			}
+		}
+	}
+	for _, p := range cfg.Parms {
+		checkLabels(fmt.Sprintf("parameter %q", p.Name), p.Labels)
+	}
+	for _, tab := range cfg.Layout.Tabs {
+		checkLabels(fmt.Sprintf("tab %q", tab.Title), tab.Labels)
+		for _, col := range tab.Columns {
+			for _, panel := range col.Panels {
+				checkLabels(fmt.Sprintf("panel %q", panel.Title), panel.Labels)
+			}
+		}
+	}
+	for _, t := range cfg.Themes {
+		checkLabels(fmt.Sprintf("theme %q", t.Name), t.Labels)
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
+
+// messages returns the text of every message key in l.
+func (l Locale) messages() map[string]string {
+	all := map[string]string{}
+	for k, v := range englishMessages {
+		all[k] = v
+	}
+	for k, v := range l.Messages {
+		all[k] = v
+	}
+	return all
+}
+
+// msg returns the English text of the message key, escaped for HTML. The page
+// shows it until i18n.js translates it.
+func msg(key string) string {
+	return html.EscapeString(englishMessages[key])
+}
+
+// localeData is what i18n.js knows about a locale.
+type localeData struct {
+	Label    string            `json:"label"`
+	Messages map[string]string `json:"messages"`
+	Titles   map[string]string `json:"titles"` // by English title
+	Parms    map[string]string `json:"parms"`  // display names by Name
+}
+
+// I18nJS returns the javascript for i18n.js, which defines SetLocale(tag).
+// SetLocale shows the page in the locale with the given tag, if the page
+// offers it, and remembers the choice in localStorage. Until one is made the
+// page follows the browser's preferred languages. The script sets the
+// document's lang attribute as soon as it runs, so it is loaded in the head,
+// and the wasm client formats numbers for that language from the start. Each
+// change of locale is announced with a "localechange" event on window whose
+// detail is the tag.
+func I18nJS(cfg Config) string {
+	var tags []string
+	data := map[string]localeData{}
+	for _, l := range cfg.locales() {
+		tags = append(tags, fmt.Sprintf("%q", l.Tag))
+		d := localeData{Label: l.Label, Messages: l.messages(), Titles: map[string]string{}, Parms: map[string]string{}}
+		title := func(english string, labels map[string]string) {
+			if label, ok := labels[l.Tag]; ok {
+				d.Titles[english] = label
+			}
+		}
+		for _, tab := range cfg.layout().Tabs {
+			title(tab.Title, tab.Labels)
+			for _, col := range tab.Columns {
+				for _, panel := range col.Panels {
+					title(panel.Title, panel.Labels)
+				}
+			}
+		}
+		for _, t := range cfg.themes() {
+			title(t.label(), t.Labels)
+		}
+		for _, p := range cfg.Parms {
+			if label, ok := p.Labels[l.Tag]; ok {
+				d.Parms[p.Name] = label
+			}
+		}
+		data[l.Tag] = d
+	}
+	// encoding/json escapes <, > and &, so the data can't end the script
+	jsn, _ := json.MarshalIndent(data, "", "\t")
+	return fmt.Sprintf(`// Code generated by wasmskel/gen. DO NOT EDIT.
+var localeTags = [%s];
+var locales = %s;
+function SetLocale(tag) {
+	if (!(tag in locales)) {
+		return;
+	}
+	try {
+		localStorage.setItem("locale", tag);
+	} catch (e) {} // storage may be disabled
+	showLocale(tag);
+}
+// showLocale translates the page into the locale with the given tag.
+function showLocale(tag) {
+	var L = locales[tag];
+	function text(key) {
+		return L.messages[key];
+	}
+	function parm(name) {
+		return L.parms[name] || name;
+	}
+	document.documentElement.lang = tag;
+	document.title = text("title");
+	document.querySelectorAll("[data-msg]").forEach(function (el) {
+		el.textContent = text(el.dataset.msg);
+	});
+	document.querySelectorAll("[data-msg-label]").forEach(function (el) {
+		el.setAttribute("aria-label", text(el.dataset.msgLabel));
+	});
+	document.querySelectorAll("[data-title]").forEach(function (el) {
+		el.textContent = L.titles[el.dataset.title] || el.dataset.title;
+	});
+	document.querySelectorAll("[data-parm-label]").forEach(function (el) {
+		el.textContent = parm(el.dataset.parmLabel);
+	});
+	document.querySelectorAll("button[data-parm]").forEach(function (el) {
+		el.setAttribute("aria-label", text("setParm").replace("{parm}", parm(el.dataset.parm)));
+	});
+	var picker = document.getElementById("LocaleSelect");
+	if (picker) {
+		picker.value = tag;
+	}
+	dispatchEvent(new CustomEvent("localechange", {detail: tag}));
+}
+// preferredLocale returns the tag of the first locale the browser prefers
+// that the page offers, matching "de-AT" to "de" if need be, else the first.
+function preferredLocale() {
+	var langs = navigator.languages || [navigator.language || ""];
+	for (var i = 0; i < langs.length; i++) {
+		if (langs[i] in locales) {
+			return langs[i];
+		}
+		var primary = langs[i].split("-")[0];
+		if (primary in locales) {
+			return primary;
+		}
+	}
+	return localeTags[0];
+}
+(function () {
+	var tag = "";
+	try {
+		tag = localStorage.getItem("locale") || "";
+	} catch (e) {}
+	if (!(tag in locales)) {
+		tag = preferredLocale();
+	}
+	document.documentElement.lang = tag;
+	document.addEventListener("DOMContentLoaded", function () {
+		var picker = document.getElementById("LocaleSelect");
+		if (picker) {
+			picker.addEventListener("change", function () {
+				SetLocale(picker.value);
+			});
+		}
+		showLocale(tag);
+	});
+})();
+`, strings.Join(tags, ", "), jsn)
+}
+
+// genI18nScript generates i18n.js in cfg.AssetsDir
+func genI18nScript(cfg Config) (f File, err error) {
+	f.Path = path.Join(cfg.AssetsDir, "i18n.js")
+	f.Data = []byte(I18nJS(cfg))
+	return
+}
+
+// LocalePicker returns a div with a dropdown for choosing a locale, each
+// labelled in its own language. i18n.js handles its changes.
+func LocalePicker(locales []Locale) *h.HtmlTree {
+	var opts []interface{}
+	for _, l := range locales {
+		opts = append(opts, h.Option(fmt.Sprintf(`value="%s" lang="%s"`, l.Tag, l.Tag), html.EscapeString(l.Label)))
+	}
+	return h.Div(`class="THEME"`,
+		h.Label(`for="LocaleSelect" data-msg="language"`, msg("language")),
+		h.Select(`id="LocaleSelect" class="THEME"`, opts...))
+}
+
+// genLocales generates locales_g.go in cfg.WasmDir. It tells the wasm client
+// how to format numbers and dates, and the text of its messages, in each
+// locale.
+func genLocales(cfg Config) (f File, err error) {
+	var b strings.Builder
+	for _, l := range cfg.locales() {
+		date := l.Date
+		if date == "" {
+			date = "2006-01-02"
+		}
+		all := l.messages()
+		fmt.Fprintf(&b, "%q: {decimal: %q, group: %q, date: %q, messages: map[string]string{\n", l.Tag, l.Decimal, l.Group, date)
+		for _, key := range clientMessages {
+			fmt.Fprintf(&b, "%q: %q,\n", key, all[key])
+		}
+		b.WriteString("}},\n")
+	}
+	tmpl := fmt.Sprintf(`
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package main
+
+	// defaultLocale is the tag of the locale used until the page chooses one.
+	const defaultLocale = %q
+
+	// locales holds the formats and messages of the locales the page offers,
+	// by tag.
+	var locales = map[string]localeFormat{
+	%s}
+	`, cfg.locales()[0].Tag, b.String())
+	f.Path = path.Join(cfg.WasmDir, "locales_g.go")
+	f.Data, err = source("locales_g.go", tmpl, cfg.data())
+	return
+}
@@ -0,0 +1,202 @@
+package gen
+
+import (
+	"path"
+)
+
+// genStateJSON generates json_g.go in cfg.CommonDir. It holds MarshalJSON and
+// UnmarshalJSON methods for State that are specialised to its fields, so the
+// server and the wasm client encode and decode it on every poll without
+// reflection. The helpers they call are in the common package's json.go.
+func genStateJSON(cfg Config) (f File, err error) {
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package {{.CommonName}}
+
+	import "strings"
+
+	// stateFields lists the names of State's fields.
+	var stateFields = []string{ {{- range $i, $p := .Parms}}{{if $i}}, {{end}}"{{$p.Name}}"{{end -}} }
+
+	// MarshalJSON encodes s exactly as encoding/json would, but without
+	// reflection.
+	func (s State) MarshalJSON() ([]byte, error) {
+		b := make([]byte, 0, 256)
+		var err error
+		{{- range $i, $p := .Parms}}
+		b = append(b, ` + "`" + `{{if $i}},{{else}}{{"{"}}{{end}}"{{.Name}}":` + "`" + `...)
+		{{- if eq .Type "float64"}}
+		b, err = appendFloat(b, s.{{.Name}})
+		if err != nil {
+			return nil, err
+		}
+		{{- else if eq .Type "int"}}
+		b = appendInt(b, s.{{.Name}})
+		{{- else if eq .Type "bool"}}
+		b = appendBool(b, s.{{.Name}})
+		{{- else}}
+		b = appendString(b, s.{{.Name}})
+		{{- end}}
+		{{- end}}
+		return append(b, '}'), err
+	}
+
+	// UnmarshalJSON decodes data into s without reflection. Like encoding/json,
+	// it matches keys to field names exactly or else ignoring case, skips
+	// unknown keys and leaves a field unchanged if its value is null.
+	func (s *State) UnmarshalJSON(data []byte) error {
+		if isNull(data) {
+			return nil
+		}
+		return scanObject(data, func(key, value []byte) error {
+			ok, err := s.decodeField(key, value)
+			if ok {
+				return err
+			}
+			for _, name := range stateFields {
+				if strings.EqualFold(name, string(key)) {
+					_, err = s.decodeField([]byte(name), value)
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	// decodeField decodes value into the field called name. It reports false if
+	// State has no such field.
+	func (s *State) decodeField(name, value []byte) (ok bool, err error) {
+		ok = true
+		switch string(name) {
+		{{- range .Parms}}
+		case "{{.Name}}":
+			{{- if eq .Type "float64"}}
+			err = decodeFloat(value, &s.{{.Name}}, "{{.Name}}")
+			{{- else if eq .Type "int"}}
+			err = decodeInt(value, &s.{{.Name}}, "{{.Name}}")
+			{{- else if eq .Type "bool"}}
+			err = decodeBool(value, &s.{{.Name}}, "{{.Name}}")
+			{{- else}}
+			err = decodeStringField(value, &s.{{.Name}}, "{{.Name}}")
+			{{- end}}
+		{{- end}}
+		default:
+			ok = false
+		}
+		return
+	}
+	`
+	f.Path = path.Join(cfg.CommonDir, "json_g.go")
+	f.Data, err = source("json_g.go", tmpl, cfg.data())
+	return
+}
+
+// genStateJSONTest generates json_g_test.go in cfg.CommonDir. It checks the
+// generated methods against encoding/json and benchmarks the two.
+func genStateJSONTest(cfg Config) (f File, err error) {
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package {{.CommonName}}
+
+	import (
+		"encoding/json"
+		"testing"
+	)
+
+	// reflectState has State's fields but not its methods, so encoding/json
+	// handles it by reflection.
+	type reflectState State
+
+	// sampleState holds a valid value in every field.
+	var sampleState = State{
+	{{- range .Parms}}
+		{{.Name}}: {{.SampleGo}},
+	{{- end}}
+	}
+
+	func TestStateJSON(t *testing.T) {
+		got, err := sampleState.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := json.Marshal(reflectState(sampleState))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("MarshalJSON gave %s, encoding/json gave %s", got, want)
+		}
+		var s State
+		if err := s.UnmarshalJSON(want); err != nil || s != sampleState {
+			t.Errorf("UnmarshalJSON(%s) gave %+v, %v", want, s, err)
+		}
+		s = State{}
+		if err := json.Unmarshal(want, &s); err != nil || s != sampleState {
+			t.Errorf("json.Unmarshal(%s) gave %+v, %v", want, s, err)
+		}
+	}
+
+	func TestStateJSONErrors(t *testing.T) {
+		bads := []string{"", "{", "[1]", "{\"x\":}", "{\"x\":1,}", "{\"x\":1} 2"}
+		// Values under unknown keys are checked too
+		bads = append(bads, "{\"x\":tru}", "{\"x\":nul}", "{\"x\":01}", "{\"x\":\"a\\qb\"}", "{\"x\":\"\\u12g4\"}",
+			"{\"x\":\"a\tb\"}", "{\"x\":{\"a\":1]}", "{\"x\":[1}", "{\"x\":[1,]}", "{\"x\":{\"a\" 1}}", "{\"x\":{1:2}}")
+		{{- range .Parms}}
+		bads = append(bads, ` + "`" + `{"{{.Name}}":{{if eq .Type "string"}}1{{else}}"x"{{end}}}` + "`" + `)
+		{{- end}}
+		for _, bad := range bads {
+			var s State
+			if err := s.UnmarshalJSON([]byte(bad)); err == nil {
+				t.Errorf("UnmarshalJSON accepted %s", bad)
+			}
+			var r reflectState
+			if err := json.Unmarshal([]byte(bad), &r); err == nil {
+				t.Errorf("encoding/json accepted %s", bad)
+			}
+		}
+		good := "{\"x\": {\"a\": [1, {\"b\": null}, []], \"c\": \"\\u00e9\\n\\\"\"}, \"y\": [true, -0.5e3, {}]}"
+		var s State
+		if err := s.UnmarshalJSON([]byte(good)); err != nil {
+			t.Errorf("UnmarshalJSON rejected %s: %v", good, err)
+		}
+		var r reflectState
+		if err := json.Unmarshal([]byte(good), &r); err != nil {
+			t.Errorf("encoding/json rejected %s: %v", good, err)
+		}
+	}
+
+	func BenchmarkMarshalJSON(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = sampleState.MarshalJSON()
+		}
+	}
+
+	func BenchmarkMarshalJSONReflect(b *testing.B) {
+		r := reflectState(sampleState)
+		for i := 0; i < b.N; i++ {
+			_, _ = json.Marshal(&r)
+		}
+	}
+
+	func BenchmarkUnmarshalJSON(b *testing.B) {
+		data, _ := sampleState.MarshalJSON()
+		for i := 0; i < b.N; i++ {
+			var s State
+			_ = s.UnmarshalJSON(data)
+		}
+	}
+
+	func BenchmarkUnmarshalJSONReflect(b *testing.B) {
+		data, _ := sampleState.MarshalJSON()
+		for i := 0; i < b.N; i++ {
+			var r reflectState
+			_ = json.Unmarshal(data, &r)
+		}
+	}
+	`
+	f.Path = path.Join(cfg.CommonDir, "json_g_test.go")
+	f.Data, err = source("json_g_test.go", tmpl, cfg.data())
+	return
+}
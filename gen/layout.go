@@ -0,0 +1,191 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// Widget kinds that show a parameter's latest value
+	Readout = "readout" // the value as text
+	Gauge   = "gauge"   // a meter scaled from Min to Max, and the value as text
+	Bar     = "bar"     // a horizontal bar scaled from Min to Max, and the value as text
+	LED     = "led"     // a lamp that is lit when the value is true or non-zero
+	Trend   = "trend"   // a line chart of recent values, and the value as text
+)
+
+// Layout arranges the parameters on the page. Each tab is a view of the app
+// holding columns of titled panels, and each panel lists parameters with the
+// widget that shows them. Parameters that aren't placed don't appear on the
+// page. A Layout with no tabs stands for DefaultLayout.
+type Layout struct {
+	Tabs []Tab
+}
+
+// Tab is one view of the app, shown when the URL fragment is "#/" followed by
+// its route. The route is derived from the title unless given.
+type Tab struct {
+	Title   string
+	Route   string
+	Labels  map[string]string // titles by locale tag, Title if absent
+	Columns []Column
+}
+
+// Column is a stack of panels. Columns sit side by side if there is room.
+type Column struct {
+	Panels []Panel
+}
+
+// Panel is a titled group of parameters.
+type Panel struct {
+	Title  string
+	Labels map[string]string // titles by locale tag, Title if absent
+	Items  []Item
+}
+
+// Item places a parameter in a panel.
+type Item struct {
+	Parm   string // the parameter's Name
+	Widget string // one of the widget kinds, Readout if empty
+}
+
+// DefaultLayout returns a layout with a single tab, Dashboard, and a single
+// panel that shows every parameter in parms as a Readout.
+func DefaultLayout(parms []Meta) Layout {
+	var items []Item
+	for _, p := range parms {
+		items = append(items, Item{Parm: p.Name, Widget: Readout})
+	}
+	panel := Panel{Title: "Parameter Values", Labels: map[string]string{"de": "Parameterwerte", "fr": "Valeurs des paramètres"}, Items: items}
+	return Layout{Tabs: []Tab{{
+		Title:   "Dashboard",
+		Labels:  map[string]string{"de": "Übersicht", "fr": "Tableau de bord"},
+		Columns: []Column{{Panels: []Panel{panel}}},
+	}}}
+}
+
+// LoadLayout reads a JSON Layout from the file at fpath, e.g.
+//
+//	{"Tabs": [{"Title": "Main", "Columns": [{"Panels": [
+//	    {"Title": "Readings", "Items": [{"Parm": "Alpha", "Widget": "trend"}]}]}]}]}
+func LoadLayout(fpath string) (layout Layout, err error) {
+	jsn, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(jsn, &layout)
+	if err != nil {
+		err = fmt.Errorf("couldn't parse layout %s: %v", fpath, err)
+	}
+	return
+}
+
+// Placement is a parameter as placed by a Layout.
+type Placement struct {
+	Meta
+	Widget string
+}
+
+// placements returns the parameters placed by layout in page order, with
+// each widget kind filled in.
+func (layout Layout) placements(parms []Meta) (placed []Placement) {
+	byName := map[string]Meta{}
+	for _, p := range parms {
+		byName[p.Name] = p
+	}
+	for _, tab := range layout.Tabs {
+		for _, col := range tab.Columns {
+			for _, panel := range col.Panels {
+				for _, item := range panel.Items {
+					placed = append(placed, Placement{byName[item.Parm], item.widget()})
+				}
+			}
+		}
+	}
+	return
+}
+
+// widget returns the widget kind for item.
+func (item Item) widget() string {
+	if item.Widget == "" {
+		return Readout
+	}
+	return item.Widget
+}
+
+// LayoutError lists every problem ValidateLayout found in a layout.
+type LayoutError []string
+
+func (e LayoutError) Error() string {
+	return "invalid layout:\n\t" + strings.Join(e, "\n\t")
+}
+
+// ValidateLayout checks that every tab has a distinct route, that every item
+// in layout names a parameter in parms, that no parameter is placed twice and
+// that each widget suits its parameter. It returns a LayoutError describing
+// all the problems found, or nil.
+func ValidateLayout(layout Layout, parms []Meta) error {
+	var problems LayoutError
+	byName := map[string]Meta{}
+	for _, p := range parms {
+		byName[p.Name] = p
+	}
+	seen := map[string]string{}
+	routes := map[string]int{HistoryRoute: 0, LogsRoute: 0}
+	for t, tab := range layout.Tabs {
+		route := tab.route()
+		switch first, ok := routes[route]; {
+		case !routeRE.MatchString(route):
+			problems = append(problems, fmt.Sprintf("tab %d %q: route %q must be lower case letters, digits and hyphens", t+1, tab.Title, route))
+		case ok && first == 0:
+			problems = append(problems, fmt.Sprintf("tab %d %q: route %q is reserved", t+1, tab.Title, route))
+		case ok:
+			problems = append(problems, fmt.Sprintf("tab %d %q: route %q is already used by tab %d", t+1, tab.Title, route, first))
+		default:
+			routes[route] = t + 1
+		}
+		for c, col := range tab.Columns {
+			for pn, panel := range col.Panels {
+				for i, item := range panel.Items {
+					where := fmt.Sprintf("tab %d column %d panel %d item %d %q", t+1, c+1, pn+1, i+1, item.Parm)
+					report := func(format string, args ...interface{}) {
+						problems = append(problems, where+": "+fmt.Sprintf(format, args...))
+					}
+					p, ok := byName[item.Parm]
+					if !ok {
+						report("no such parameter")
+						continue
+					}
+					if first, ok := seen[item.Parm]; ok {
+						report("already placed at %s", first)
+					} else {
+						seen[item.Parm] = where
+					}
+					switch item.widget() {
+					case Readout:
+					case Gauge, Bar:
+						if !p.Bounded() {
+							report("widget %q requires a numeric type with Min < Max", item.Widget)
+						}
+					case Trend:
+						if !p.Numeric() {
+							report("widget %q requires a numeric type", item.Widget)
+						}
+					case LED:
+						if !p.Numeric() && p.Type != Bool {
+							report("widget %q requires a numeric or %s type", item.Widget, Bool)
+						}
+					default:
+						report("unknown widget %q", item.Widget)
+					}
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
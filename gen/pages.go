@@ -0,0 +1,158 @@
+package gen
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"strings"
+
+	h "github.com/Michael-F-Ellis/goht"
+)
+
+// Routes of the views every app has besides those of the layout
+const (
+	HistoryRoute = "history"
+	LogsRoute    = "logs"
+)
+
+// Page is one view of the app. All the views are generated into index.html
+// and share the wasm client and its connection to the server. The client
+// shows the view whose Route follows "#/" in the URL fragment, or the first
+// view if no route matches.
+type Page struct {
+	Route   string
+	Title   string // shown in the navigation bar
+	Key     string // message key of Title, "" if its tab's Labels translate it
+	Content *h.HtmlTree
+}
+
+// Pages returns the views of the app described by cfg: one for each tab of
+// the layout, then the history of recent values and the log of server
+// requests.
+func Pages(cfg Config) (pages []Page) {
+	byName := map[string]Meta{}
+	for _, p := range cfg.Parms {
+		byName[p.Name] = p
+	}
+	for _, tab := range cfg.layout().Tabs {
+		var cols []interface{}
+		for _, col := range tab.Columns {
+			var panels []interface{}
+			for _, panel := range col.Panels {
+				panels = append(panels, PanelDiv(panel, byName))
+			}
+			cols = append(cols, h.Div(`class="COLUMN"`, panels...))
+		}
+		pages = append(pages, Page{tab.route(), tab.Title, "", h.Div(`class="COLUMNS"`, cols...)})
+	}
+	pages = append(pages,
+		Page{HistoryRoute, englishMessages["history"], "history", HistoryView(cfg.Parms)},
+		Page{LogsRoute, englishMessages["logs"], "logs", LogsView()})
+	return
+}
+
+// routeRE matches the routes that are safe to use unquoted in ids and URLs.
+var routeRE = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// nonRouteRE matches runs of characters that can't be part of a route.
+var nonRouteRE = regexp.MustCompile(`[^a-z0-9]+`)
+
+// route returns the route of the view showing t, which is t.Route if given,
+// else t.Title in lower case with each run of other characters replaced by
+// a hyphen.
+func (t Tab) route() string {
+	if t.Route != "" {
+		return t.Route
+	}
+	return strings.Trim(nonRouteRE.ReplaceAllString(strings.ToLower(t.Title), "-"), "-")
+}
+
+// NavBar returns a nav element with a link to each of pages. The wasm client
+// marks the link to the view it shows as active and as the current page.
+// i18n.js translates the titles.
+func NavBar(pages []Page) (nav *h.HtmlTree) {
+	var links []interface{}
+	for i, p := range pages {
+		title := html.EscapeString(p.Title)
+		attrs := fmt.Sprintf(`id="Nav-%s" class="NAV" href="#/%s" aria-current="false"`, p.Route, p.Route)
+		if i == 0 {
+			attrs = fmt.Sprintf(`id="Nav-%s" class="NAV active" href="#/%s" aria-current="page"`, p.Route, p.Route)
+		}
+		if p.Key != "" {
+			attrs += fmt.Sprintf(` data-msg="%s"`, p.Key)
+		} else {
+			attrs += fmt.Sprintf(` data-title="%s"`, title)
+		}
+		links = append(links, h.A(attrs, title))
+	}
+	nav = h.Nav(`class="NAV" aria-label="Views" data-msg-label="views"`, links...)
+	return
+}
+
+// ViewsDiv returns a div holding a div for each of pages, with only the first
+// visible until the wasm client applies the route. The outer div's id,
+// ParmTable, lets the client grey the readouts inside it when they are stale.
+func ViewsDiv(pages []Page) (div *h.HtmlTree) {
+	var views []interface{}
+	for i, p := range pages {
+		class := "VIEW"
+		if i > 0 {
+			class = "VIEW inactive"
+		}
+		views = append(views, h.Div(fmt.Sprintf(`id="View-%s" class="%s"`, p.Route, class), p.Content))
+	}
+	div = h.Div(`id="ParmTable" class="PARM"`, views...)
+	return
+}
+
+// HistoryView returns a table with a column for each parameter. The wasm
+// client fills its body, HistoryRows, with the most recent values it has
+// fetched.
+func HistoryView(parms []Meta) (tbl *h.HtmlTree) {
+	heads := []interface{}{h.Th(`scope="col" data-msg="time"`, msg("time"))}
+	for _, p := range parms {
+		heads = append(heads, h.Th(`scope="col"`, ParmName(p)))
+	}
+	tbl = h.Table(`class="HISTORY"`, h.Thead(``, h.Tr(``, heads...)), h.Tbody(`id="HistoryRows" class="PARM"`))
+	return
+}
+
+// LogsView returns a list that the wasm client fills with the outcomes of
+// set requests, failed gets and changes of link state, newest first.
+func LogsView() (ul *h.HtmlTree) {
+	ul = h.Ul(`id="LogList" class="LOG"`)
+	return
+}
+
+// genPages generates pages_g.go in cfg.WasmDir. It tells the wasm client the
+// routes of the views and how to fill a row of the history table.
+func genPages(cfg Config) (f File, err error) {
+	var routes []string
+	for _, p := range Pages(cfg) {
+		routes = append(routes, fmt.Sprintf("%q", p.Route))
+	}
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package main
+
+	import "{{.CommonImport}}"
+
+	// routes are the routes of the app's views in navigation order.
+	var routes = []string{` + strings.Join(routes, ", ") + `}
+
+	// historyCells returns the values in s formatted for the history table in
+	// the current locale.
+	func historyCells(s *{{.CommonName}}.State) []string {
+		return []string{
+		{{- range .Parms}}
+			{{.DisplayGo "s"}},
+		{{- end}}
+		}
+	}
+	`
+	f.Path = path.Join(cfg.WasmDir, "pages_g.go")
+	f.Data, err = source("pages_g.go", tmpl, cfg.data())
+	return
+}
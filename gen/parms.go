@@ -0,0 +1,303 @@
+package gen
+
+import (
+	"path"
+)
+
+// genState generates state_g.go in cfg.CommonDir, the data definitions shared
+// by the server and the web client.
+func genState(cfg Config) (f File, err error) {
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package {{.CommonName}}
+
+	type State struct {
+	{{range .Parms}}
+	    {{.Name}} {{.Type}}
+		{{- end}}
+	}
+	`
+	f.Path = path.Join(cfg.CommonDir, "state_g.go")
+	f.Data, err = source("state_g.go", tmpl, cfg.data())
+	return
+}
+
+// genParms generates parms_g.go in cfg.CommonDir. It holds the decoding and
+// constraint checks for settable parameters that both the server and the
+// web client apply before accepting a new value.
+func genParms(cfg Config) (f File, err error) {
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package {{.CommonName}}
+
+	import (
+		"bytes"
+		"fmt"
+		"strings"
+	)
+
+	// DecodeParm unmarshals raw as a value for the named settable parameter and
+	// checks it against the schema constraints. It uses the same
+	// reflection-free decoders as State.UnmarshalJSON.
+	func DecodeParm(name string, raw []byte) (value interface{}, err error) {
+		raw = bytes.TrimSpace(raw)
+		if isNull(raw) {
+			err = fmt.Errorf("null is not a valid value for %s", name)
+			return
+		}
+		switch name {
+		{{- range .Parms}}{{if .Settable}}
+		case "{{.Name}}":
+			var v {{.Type}}
+			err = {{.DecodeFunc}}(raw, &v, "{{.Name}}")
+			if err != nil {
+				err = fmt.Errorf("couldn't unmarshal value for {{.Name}}: %v", err)
+				return
+			}
+			err = check{{.Name}}(v)
+			if err == nil {
+				value = v
+			}
+		{{- end}}{{end}}
+		default:
+			err = fmt.Errorf("%s is not a settable parameter", name)
+		}
+		return
+	}
+
+	// Apply decodes raw as a new value for the named parameter, checks it
+	// against the schema and stores it in sp. The server's Dispatcher and the
+	// wasm client's simulation mode both use it.
+	func (sp *State) Apply(name string, raw []byte) (err error) {
+		switch name {
+		{{- range .Parms}}
+		case "{{.Name}}":
+			{{- if .Settable}}
+			var value interface{}
+			value, err = DecodeParm("{{.Name}}", raw)
+			if err != nil {
+				return
+			}
+			sp.DirectUpdate(func(p *State) { p.{{.Name}} = value.({{.Type}}) })
+			{{- else}}
+			err = UnsettableErr("{{.Name}}")
+			{{- end}}
+		{{- end}}
+		default:
+			err = fmt.Errorf("%s is not a parameter", name)
+		}
+		return
+	}
+
+	// ParseParm converts text, as read from the named parameter's input control,
+	// to a value of the parameter's type and checks it against the schema
+	// constraints.
+	func ParseParm(name, text string) (value interface{}, err error) {
+		text = strings.TrimSpace(text)
+		raw := []byte(text)
+		switch name {
+		{{- range .Parms}}{{if and .Settable (eq .Type "string")}}
+		case "{{.Name}}":
+			raw = appendString(nil, text)
+		{{- end}}{{end}}
+		default:
+			if !isLiteral(raw) {
+				err = fmt.Errorf("%q is not a valid value for %s", text, name)
+				return
+			}
+		}
+		value, err = DecodeParm(name, raw)
+		return
+	}
+	{{range .Parms}}{{if .Settable}}
+	// check{{.Name}} returns an error if v violates the constraints on {{.Name}}.
+	func check{{.Name}}(v {{.Type}}) (err error) {
+		{{- if .Bounded}}
+		if v < {{.Min}} || v > {{.Max}} {
+			err = fmt.Errorf("{{.Name}} must be between {{.Min}} and {{.Max}}, got %v", v)
+		}
+		{{- else if eq .Type "string"}}
+		switch v {
+		case {{range $i, $o := .Options}}{{if $i}}, {{end}}{{printf "%q" $o}}{{end}}:
+		default:
+			err = fmt.Errorf("%q is not one of the options for {{.Name}}", v)
+		}
+		{{- end}}
+		return
+	}
+	{{end}}{{end}}
+	`
+	f.Path = path.Join(cfg.CommonDir, "parms_g.go")
+	f.Data, err = source("parms_g.go", tmpl, cfg.data())
+	return
+}
+
+// genUpdater generates updater_g.go in cfg.WasmDir
+func genUpdater(cfg Config) (f File, err error) {
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package main
+
+	import "fmt"
+
+	// UpdateParmReadouts copies the current values from the global state into
+	// the widgets that show them on the page. Readouts show the value as text.
+	// The client draws the graphical widgets as SVG.
+	func UpdateParmReadouts(){
+	var err error
+	{{range .Placed}}
+	{{- if eq .Widget "gauge"}}
+	err = setElementAttributeById("{{.Name}}", "innerHTML", gaugeSVG(float64(SP.{{.Name}}), {{.Min}}, {{.Max}}, {{template "text" .}}))
+	{{- else if eq .Widget "bar"}}
+	err = setElementAttributeById("{{.Name}}", "innerHTML", barSVG(float64(SP.{{.Name}}), {{.Min}}, {{.Max}}, {{template "text" .}}))
+	{{- else if eq .Widget "led"}}
+	err = setElementAttributeById("{{.Name}}", "innerHTML", lampSVG({{if eq .Type "bool"}}SP.{{.Name}}{{else}}SP.{{.Name}} != 0{{end}}))
+	{{- else}}
+	err = setElementAttributeById("{{.Name}}", "textContent", {{template "text" .}})
+	{{- end}}
+	if err != nil {
+		fmt.Println(err)
+	}
+	{{- if eq .Widget "trend"}}
+	err = setElementAttributeById("{{.Name}}-trend", "innerHTML", trends.svg("{{.Name}}", {{.Min}}, {{.Max}}))
+	if err != nil {
+		fmt.Println(err)
+	}
+	{{- end}}
+	{{- end}}
+	return
+	}
+
+	// RecordTrends adds the current values from the global state to the trend
+	// widgets' charts. Only states fresh from the server are recorded, so
+	// UpdateParmReadouts just redraws them.
+	func RecordTrends(){
+	{{- range .Placed}}
+	{{- if eq .Widget "trend"}}
+	trends.add("{{.Name}}", float64(SP.{{.Name}}))
+	{{- end}}
+	{{- end}}
+	}
+
+	// FillParmInputs sets the input controls of the settable parameters to
+	// their values in the global state, so that a Set button sends what the
+	// server holds rather than the browser's default for the control.
+	func FillParmInputs(){
+	var err error
+	{{range .Placed}}
+	{{- if .Settable}}
+	err = setElementAttributeById("{{.Name}}-input", {{if eq .Type "bool"}}"checked"{{else}}"value"{{end}}, fmt.Sprint(SP.{{.Name}}))
+	if err != nil {
+		fmt.Println(err)
+	}
+	{{- end}}
+	{{- end}}
+	return
+	}
+	{{define "text"}}{{.DisplayGo "SP"}}{{end}}
+	`
+	f.Path = path.Join(cfg.WasmDir, "updater_g.go")
+	f.Data, err = source("updater_g.go", tmpl, cfg.data())
+	return
+}
+
+// genDispatcher generates dispatch_g.go in cfg.ServerDir
+func genDispatcher(cfg Config) (f File, err error) {
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package main
+
+	import (
+		"encoding/json"
+
+		"{{.CommonImport}}"
+	)
+
+	// UnsettableErr returns an err whose string value indicates an attempt to
+	// set an unsettable variable
+	func UnsettableErr(varName string) error {
+		return {{.CommonName}}.UnsettableErr(varName)
+	}
+
+	// Dispatcher invokes the setter function for the requested jsonName
+	func Dispatcher(jsonName string, rawval json.RawMessage) (err error) {
+		return State.Apply(jsonName, rawval)
+	}
+	`
+	f.Path = path.Join(cfg.ServerDir, "dispatch_g.go")
+	f.Data, err = source("dispatch_g.go", tmpl, cfg.data())
+	return
+}
+
+// genDispatcherTest generates dispatch_g_test.go in cfg.ServerDir, a table
+// driven test of the generated Dispatcher.
+func genDispatcherTest(cfg Config) (f File, err error) {
+	tmpl := `
+	// Code generated by wasmskel/gen. DO NOT EDIT.
+
+	package main
+
+	import (
+		"encoding/json"
+		"net/http"
+		"net/http/httptest"
+		"strings"
+		"testing"
+
+		"{{.CommonImport}}"
+	)
+
+	func TestDispatcher(t *testing.T) {
+		tests := []struct {
+			name     string
+			settable bool
+			valid    string                          // JSON for a valid value
+			invalid  string                          // well formed JSON that violates the schema, if any
+			get      func(p *{{.CommonName}}.State) interface{} // reads the parameter
+			want     interface{}                     // expected value after setting valid
+		}{
+		{{- range .Parms}}
+			{"{{.Name}}", {{.Settable}}, {{printf "%q" .SampleJSON}}, {{printf "%q" .InvalidJSON}},
+			func(p *{{$.CommonName}}.State) interface{} { return p.{{.Name}} }, {{.SampleGo}}},
+		{{- end}}
+		}
+		malformed := []string{"{", "", "null", "[1]", "{\"x\":1}"}
+		for _, tc := range tests {
+			// A null value must be rejected, not crash the handler
+			w := httptest.NewRecorder()
+			setRequestHandler(w, httptest.NewRequest("POST", "/set", strings.NewReader(` + "`" + `{"` + "`" + `+tc.name+` + "`" + `":null}` + "`" + `)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected a null value to get status %d, got %d", tc.name, http.StatusBadRequest, w.Code)
+			}
+			err := Dispatcher(tc.name, json.RawMessage(tc.valid))
+			if !tc.settable {
+				if err == nil || err.Error() != UnsettableErr(tc.name).Error() {
+					t.Errorf("%s: expected UnsettableErr, got %v", tc.name, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: unexpected error setting %s: %v", tc.name, tc.valid, err)
+			} else if got := tc.get(State.Get()); got != tc.want {
+				t.Errorf("%s: expected State to hold %v after set, got %v", tc.name, tc.want, got)
+			}
+			bads := malformed
+			if tc.invalid != "" {
+				bads = append(bads, tc.invalid)
+			}
+			for _, bad := range bads {
+				if err := Dispatcher(tc.name, json.RawMessage(bad)); err == nil {
+					t.Errorf("%s: expected %q to be rejected", tc.name, bad)
+				}
+			}
+		}
+	}
+	`
+	f.Path = path.Join(cfg.ServerDir, "dispatch_g_test.go")
+	f.Data, err = source("dispatch_g_test.go", tmpl, cfg.data())
+	return
+}
@@ -0,0 +1,165 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// The app can be installed from the browser as a Progressive Web App. The
+// manifest describes it to the browser, and the service worker keeps copies
+// of the page, its scripts and the wasm client so that it starts without the
+// server. The client then shows the last state it saved, marked as offline,
+// until the server answers.
+
+// appTitle names the app on the page and when installed.
+const appTitle = "Wasm Skeleton Demo"
+
+// manifest is the content of manifest.webmanifest.
+type manifest struct {
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	ShortName       string         `json:"short_name"`
+	StartURL        string         `json:"start_url"`
+	Scope           string         `json:"scope"`
+	Display         string         `json:"display"`
+	BackgroundColor string         `json:"background_color"`
+	ThemeColor      string         `json:"theme_color"`
+	Icons           []manifestIcon `json:"icons"`
+}
+
+// manifestIcon is an icon listed in the manifest.
+type manifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose"`
+}
+
+// genManifest generates manifest.webmanifest in cfg.AssetsDir. The installed
+// app opens without the browser's controls, in the colours of the first
+// theme.
+func genManifest(cfg Config) (f File, err error) {
+	theme := cfg.themes()[0]
+	m := manifest{
+		ID:              "/",
+		Name:            appTitle,
+		ShortName:       "Wasmskel",
+		StartURL:        "/",
+		Scope:           "/",
+		Display:         "standalone",
+		BackgroundColor: theme.Background,
+		ThemeColor:      theme.Accent,
+		Icons:           []manifestIcon{{Src: "/icon.svg", Sizes: "any", Type: "image/svg+xml", Purpose: "any"}},
+	}
+	f.Path = path.Join(cfg.AssetsDir, "manifest.webmanifest")
+	f.Data, err = json.MarshalIndent(m, "", "  ")
+	f.Data = append(f.Data, '\n')
+	return
+}
+
+// iconSVG is the app's icon, a gauge, in the colours of theme.
+func iconSVG(theme Theme) string {
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100">
+<rect width="100" height="100" rx="20" fill="%s"/>
+<path d="M20,65 A30,30 0 0 1 80,65" fill="none" stroke="%s" stroke-width="10"/>
+<path d="M20,65 A30,30 0 0 1 65,39" fill="none" stroke="%s" stroke-width="10"/>
+</svg>
+`, theme.Background, theme.Border, theme.Accent)
+}
+
+// genIcon generates icon.svg in cfg.AssetsDir
+func genIcon(cfg Config) (f File, err error) {
+	f.Path = path.Join(cfg.AssetsDir, "icon.svg")
+	f.Data = []byte(iconSVG(cfg.themes()[0]))
+	return
+}
+
+// shellAssets returns the paths of the files the page needs to start, which
+// the service worker keeps for offline use. "/" stands for the page.
+func shellAssets(cfg Config) []string {
+	assets := []string{"/", "/style.css", "/theme.js", "/i18n.js", "/app.js", "/wasm_exec.js", "/app.wasm", "/manifest.webmanifest", "/icon.svg"}
+	if cfg.Worker {
+		assets = append(assets, "/worker.js")
+	}
+	return assets
+}
+
+// serviceWorkerJS is the javascript for sw.js, less the list of shell
+// assets. It fetches the shell from the server when it can, so that a new
+// build takes effect at once, and stores each copy it gets. It falls back on
+// the stored copies when the server can't be reached. Other requests, such as
+// /get and /set, go straight to the server.
+const serviceWorkerJS = `
+const CACHE = "shell";
+self.addEventListener("install", function (e) {
+	e.waitUntil(caches.open(CACHE).then(function (cache) {
+		return cache.addAll(SHELL);
+	}).then(function () {
+		return self.skipWaiting();
+	}));
+});
+// Drop copies of assets the current build doesn't use
+self.addEventListener("activate", function (e) {
+	e.waitUntil(caches.open(CACHE).then(function (cache) {
+		return cache.keys().then(function (requests) {
+			return Promise.all(requests.filter(function (r) {
+				return SHELL.indexOf(new URL(r.url).pathname) < 0;
+			}).map(function (r) {
+				return cache.delete(r);
+			}));
+		});
+	}).then(function () {
+		return self.clients.claim();
+	}));
+});
+self.addEventListener("fetch", function (e) {
+	const url = new URL(e.request.url);
+	if (e.request.method !== "GET" || url.origin !== location.origin) {
+		return;
+	}
+	// The page may be opened with a query, e.g. ?sim, or as /index.html
+	let key = url.pathname;
+	if (key === "/index.html") {
+		key = "/";
+	}
+	if (SHELL.indexOf(key) < 0) {
+		return;
+	}
+	e.respondWith(fetch(e.request).then(function (resp) {
+		if (resp.ok && url.search === "") {
+			const copy = resp.clone();
+			caches.open(CACHE).then(function (cache) {
+				cache.put(key, copy);
+			});
+		}
+		return resp;
+	}).catch(function () {
+		return caches.match(key).then(function (resp) {
+			return resp || Response.error();
+		});
+	}));
+});
+`
+
+// genServiceWorker generates sw.js in cfg.AssetsDir
+func genServiceWorker(cfg Config) (f File, err error) {
+	var quoted []string
+	for _, a := range shellAssets(cfg) {
+		quoted = append(quoted, fmt.Sprintf("%q", a))
+	}
+	f.Path = path.Join(cfg.AssetsDir, "sw.js")
+	f.Data = []byte("// Code generated by wasmskel/gen. DO NOT EDIT.\n" +
+		"// SHELL lists the files the page needs to start.\n" +
+		"const SHELL = [" + strings.Join(quoted, ", ") + "];" + serviceWorkerJS)
+	return
+}
+
+// registerJS registers the service worker. It is part of app.js.
+const registerJS = `if ("serviceWorker" in navigator) {
+	navigator.serviceWorker.register("/sw.js").catch(function (err) {
+		console.log("service worker not registered:", err);
+	});
+}
+`
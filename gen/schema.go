@@ -0,0 +1,253 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Type name constants for code generation
+	Float = "float64"
+	Int   = "int"
+	Bool  = "bool"
+	Enum  = "string" // value must be one of Meta.Options
+)
+
+// Meta instances define each parameter the app supports.
+type Meta struct {
+	Name     string
+	Type     string
+	Settable bool
+	Min, Max float64           // bounds for numeric types, ignored unless Min < Max
+	Step     float64           // increment for numeric input controls, 0 for default
+	Slider   bool              // use a range input rather than a number input
+	Options  []string          // allowed values of an Enum
+	Labels   map[string]string // display names by locale tag, Name if absent
+}
+
+// Numeric reports whether m holds a number.
+func (m Meta) Numeric() bool {
+	return m.Type == Float || m.Type == Int
+}
+
+// Bounded reports whether m has a valid range.
+func (m Meta) Bounded() bool {
+	return m.Numeric() && m.Min < m.Max
+}
+
+// SampleJSON returns a JSON value that satisfies m's constraints. The
+// generated tests use it.
+func (m Meta) SampleJSON() string {
+	switch m.Type {
+	case Float:
+		if m.Bounded() {
+			return strconv.FormatFloat((m.Min+m.Max)/2, 'g', -1, 64)
+		}
+		return "12.5"
+	case Int:
+		if m.Bounded() {
+			return strconv.Itoa(int(m.Min+m.Max) / 2)
+		}
+		return "12"
+	case Bool:
+		return "true"
+	case Enum:
+		if len(m.Options) > 0 {
+			return strconv.Quote(m.Options[len(m.Options)-1])
+		}
+	}
+	return ""
+}
+
+// SampleGo returns the Go expression for SampleJSON.
+func (m Meta) SampleGo() string {
+	switch m.Type {
+	case Float, Int:
+		return m.Type + "(" + m.SampleJSON() + ")"
+	}
+	return m.SampleJSON()
+}
+
+// InvalidJSON returns a well formed JSON value of m's type that violates its
+// constraints, or "" if m has none.
+func (m Meta) InvalidJSON() string {
+	switch {
+	case m.Bounded() && m.Type == Float:
+		return strconv.FormatFloat(m.Max+1, 'g', -1, 64)
+	case m.Bounded():
+		return strconv.Itoa(int(m.Max) + 1)
+	case m.Type == Enum:
+		return strconv.Quote(strings.Join(m.Options, "|") + "?")
+	}
+	return ""
+}
+
+// DisplayGo returns the Go expression with which the wasm client formats the
+// value of m in the State that expr points to, for display in the current
+// locale.
+func (m Meta) DisplayGo(expr string) string {
+	v := expr + "." + m.Name
+	switch m.Type {
+	case Float:
+		return "formatFloat(" + v + ", 2)"
+	case Int:
+		return "formatInt(" + v + ")"
+	case Bool:
+		return "formatBool(" + v + ")"
+	}
+	return v
+}
+
+// DecodeFunc returns the name of the function in the common package that
+// decodes a JSON value of m's type.
+func (m Meta) DecodeFunc() string {
+	switch m.Type {
+	case Float:
+		return "decodeFloat"
+	case Int:
+		return "decodeInt"
+	case Bool:
+		return "decodeBool"
+	}
+	return "decodeStringField"
+}
+
+// LoadSchema reads a JSON array of Meta objects from the file at fpath, e.g.
+//
+//	[{"Name": "Alpha", "Type": "float64"},
+//	 {"Name": "Gamma", "Type": "float64", "Settable": true}]
+func LoadSchema(fpath string) (parms []Meta, err error) {
+	jsn, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(jsn, &parms)
+	if err != nil {
+		err = fmt.Errorf("couldn't parse schema %s: %v", fpath, err)
+	}
+	return
+}
+
+// supportedTypes are the parameter types the code templates know how to
+// handle.
+var supportedTypes = map[string]bool{
+	Float: true,
+	Int:   true,
+	Bool:  true,
+	Enum:  true,
+}
+
+// reservedNames may not be used as parameter names. The ids are used by
+// elements of the generated page and the methods are defined on State.
+var reservedNames = map[string]string{
+	"GetMsg":        "element id used by the status table",
+	"SetMsg":        "element id used by the status table",
+	"LinkStatus":    "element id used by the link status indicator",
+	"ParmTable":     "element id used by the parameter table",
+	"ThemeSelect":   "element id used by the theme picker",
+	"HistoryRows":   "element id used by the history view",
+	"LogList":       "element id used by the logs view",
+	"OfflineBanner": "element id used by the offline banner",
+	"KeyHelp":       "element id used by the keyboard help",
+	"LocaleSelect":  "element id used by the locale picker",
+	"Get":           "method of State",
+	"DirectUpdate":  "method of State",
+	"Apply":         "method of State",
+	"MarshalJSON":   "method of State",
+	"UnmarshalJSON": "method of State",
+}
+
+// SchemaError lists every problem Validate found in a schema.
+type SchemaError []string
+
+func (e SchemaError) Error() string {
+	return "invalid schema:\n\t" + strings.Join(e, "\n\t")
+}
+
+// Validate checks that every parameter has a unique name that is an exported
+// Go identifier and not reserved, that its type is supported and that its
+// constraints make sense for the type. It returns a SchemaError describing all
+// the problems found, or nil.
+func Validate(parms []Meta) error {
+	var problems SchemaError
+	report := func(i int, p Meta, format string, args ...interface{}) {
+		msg := fmt.Sprintf("parameter %d %q: ", i+1, p.Name) + fmt.Sprintf(format, args...)
+		problems = append(problems, msg)
+	}
+	if len(parms) == 0 {
+		problems = append(problems, "no parameters defined")
+	}
+	seen := map[string]int{}
+	for i, p := range parms {
+		switch {
+		case !token.IsIdentifier(p.Name):
+			report(i, p, "name is not a valid Go identifier")
+		case !token.IsExported(p.Name):
+			report(i, p, "name must begin with an upper case letter")
+		}
+		if why, ok := reservedNames[p.Name]; ok {
+			report(i, p, "name is reserved (%s)", why)
+		}
+		if first, ok := seen[p.Name]; ok {
+			report(i, p, "duplicate name, first used by parameter %d", first+1)
+		} else {
+			seen[p.Name] = i
+		}
+		if !supportedTypes[p.Type] {
+			report(i, p, "unsupported type %q", p.Type)
+		}
+		validateConstraints(i, p, report)
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// validateConstraints reports constraints that don't suit the type of p.
+func validateConstraints(i int, p Meta, report func(int, Meta, string, ...interface{})) {
+	if !p.Numeric() {
+		if p.Min != 0 || p.Max != 0 || p.Step != 0 || p.Slider {
+			report(i, p, "Min, Max, Step and Slider apply only to numeric types")
+		}
+	} else {
+		if p.Max < p.Min {
+			report(i, p, "Max %v is less than Min %v", p.Max, p.Min)
+		}
+		if p.Step < 0 {
+			report(i, p, "Step may not be negative")
+		}
+		if p.Type == Int && (p.Min != float64(int(p.Min)) || p.Max != float64(int(p.Max))) {
+			report(i, p, "Min and Max must be whole numbers for type %s", Int)
+		}
+		if p.Slider && !p.Bounded() {
+			report(i, p, "Slider requires Min < Max")
+		}
+		if p.Slider && !p.Settable {
+			report(i, p, "Slider requires a settable parameter")
+		}
+	}
+	if p.Type != Enum {
+		if len(p.Options) > 0 {
+			report(i, p, "Options apply only to type %q", Enum)
+		}
+		return
+	}
+	if len(p.Options) == 0 {
+		report(i, p, "type %q requires Options", Enum)
+	}
+	seen := map[string]bool{}
+	for _, opt := range p.Options {
+		switch {
+		case opt == "":
+			report(i, p, "empty option")
+		case seen[opt]:
+			report(i, p, "duplicate option %q", opt)
+		}
+		seen[opt] = true
+	}
+}
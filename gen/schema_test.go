@@ -0,0 +1,49 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(testParms); err != nil {
+		t.Errorf("expected valid schema, got %v", err)
+	}
+	parms := []Meta{
+		{Name: "Alpha", Type: Float},
+		{Name: "alpha", Type: Float},
+		{Name: "Bad Name", Type: Float},
+		{Name: "GetMsg", Type: Float},
+		{Name: "Alpha", Type: "complex128"},
+		{Name: "Epsilon", Type: Float, Min: 2, Max: 1, Slider: true},
+		{Name: "Zeta", Type: Bool, Step: 1},
+		{Name: "Eta", Type: Enum, Options: []string{"A", "A"}},
+		{Name: "Theta", Type: Enum},
+	}
+	err := Validate(parms)
+	se, ok := err.(SchemaError)
+	if !ok {
+		t.Fatalf("expected a SchemaError, got %v", err)
+	}
+	expect := []string{
+		`parameter 2 "alpha": name must begin with an upper case letter`,
+		`parameter 3 "Bad Name": name is not a valid Go identifier`,
+		`parameter 4 "GetMsg": name is reserved`,
+		`parameter 5 "Alpha": duplicate name, first used by parameter 1`,
+		`parameter 5 "Alpha": unsupported type "complex128"`,
+		`parameter 6 "Epsilon": Max 1 is less than Min 2`,
+		`parameter 6 "Epsilon": Slider requires Min < Max`,
+		`parameter 6 "Epsilon": Slider requires a settable parameter`,
+		`parameter 7 "Zeta": Min, Max, Step and Slider apply only to numeric types`,
+		`parameter 8 "Eta": duplicate option "A"`,
+		`parameter 9 "Theta": type "string" requires Options`,
+	}
+	if len(se) != len(expect) {
+		t.Errorf("expected %d problems, got %d: %v", len(expect), len(se), err)
+	}
+	for _, want := range expect {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// source executes the Go code template tmpl with data and formats the result.
+// When either step fails, source regenerates with one parameter at a time so
+// the error can name the parameters that trigger it.
+func source(name, tmpl string, data tmplData) (src []byte, err error) {
+	t, err := template.New(name).Parse(tmpl)
+	if err != nil {
+		err = fmt.Errorf("%s: %v", name, err)
+		return
+	}
+	src, err = execFormat(t, data)
+	if err == nil {
+		return
+	}
+	var culprits []string
+	for _, parm := range data.Parms {
+		one := data
+		one.Parms = []Meta{parm}
+		if _, e := execFormat(t, one); e != nil {
+			culprits = append(culprits, parm.Name)
+		}
+	}
+	if len(culprits) > 0 {
+		err = fmt.Errorf("%s: parameter %s: %v", name, strings.Join(culprits, ", "), err)
+		return
+	}
+	err = fmt.Errorf("%s: %v", name, err)
+	return
+}
+
+// execFormat executes t with data and runs the output through go/format. A
+// syntax error is reported with the text of the line it refers to.
+func execFormat(t *template.Template, data tmplData) (src []byte, err error) {
+	var buf bytes.Buffer
+	err = t.Execute(&buf, data)
+	if err != nil {
+		return
+	}
+	src, err = format.Source(buf.Bytes())
+	if el, ok := err.(scanner.ErrorList); ok && len(el) > 0 {
+		lines := strings.Split(buf.String(), "\n")
+		if n := el[0].Pos.Line; n > 0 && n <= len(lines) {
+			err = fmt.Errorf("%v\n\t%s", el[0], strings.TrimSpace(lines[n-1]))
+		}
+	}
+	return
+}
+
+// writeFile writes f.Data to a temporary file beside f.Path and renames it
+// into place, so readers never see a partially written file.
+func writeFile(f File) (err error) {
+	dir, base := filepath.Split(f.Path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, "."+base+".*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	_, err = tmp.Write(f.Data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return
+	}
+	err = os.Chmod(tmp.Name(), 0644)
+	if err != nil {
+		return
+	}
+	err = os.Rename(tmp.Name(), f.Path)
+	return
+}
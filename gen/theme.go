@@ -0,0 +1,188 @@
+package gen
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	h "github.com/Michael-F-Ellis/goht"
+)
+
+// Theme is a named set of colours for the page. IndexCSS turns each field
+// into a CSS custom property that the rest of the stylesheet uses.
+type Theme struct {
+	Name       string            // stored in localStorage and used in the data-theme attribute
+	Label      string            // shown in the theme picker
+	Labels     map[string]string // Label by locale tag
+	Scheme     string            // "light" or "dark", for the browser's own controls
+	Background string
+	Text       string
+	Muted      string // stale readouts
+	Border     string
+	Input      string // background of input controls
+	Accent     string // filled part of gauges and bars
+	Connected  string // link status colours
+	Degraded   string
+	Offline    string
+	Fail       string // failed requests
+}
+
+// DefaultThemes are the themes the page offers unless Config.Themes says
+// otherwise. The first is used when the user hasn't chosen one, unless the
+// browser prefers a dark scheme and there is a dark theme.
+var DefaultThemes = []Theme{
+	{
+		Name: "light", Label: "Light", Scheme: "light", Labels: map[string]string{"de": "Hell", "fr": "Clair"},
+		Background: "#ffffff", Text: "#000000", Muted: "grey", Border: "#cccccc", Input: "#ffffff", Accent: "steelblue",
+		Connected: "darkgreen", Degraded: "darkorange", Offline: "darkred", Fail: "darkred",
+	},
+	{
+		Name: "dark", Label: "Dark", Scheme: "dark", Labels: map[string]string{"de": "Dunkel", "fr": "Sombre"},
+		Background: "#1e1e1e", Text: "#e0e0e0", Muted: "#888888", Border: "#444444", Input: "#2d2d2d", Accent: "#4a90d9",
+		Connected: "#6ccf6c", Degraded: "#f0a030", Offline: "#ff6b6b", Fail: "#ff6b6b",
+	},
+	{
+		Name: "contrast", Label: "High contrast", Scheme: "dark", Labels: map[string]string{"de": "Hoher Kontrast", "fr": "Contraste élevé"},
+		Background: "#000000", Text: "#ffffff", Muted: "#c0c0c0", Border: "#ffffff", Input: "#000000", Accent: "#00ffff",
+		Connected: "#00ff00", Degraded: "#ffff00", Offline: "#ff4040", Fail: "#ff4040",
+	},
+}
+
+// themeNameRE matches the theme names that are safe to use unquoted in CSS,
+// HTML and javascript.
+var themeNameRE = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// validateThemes checks that there is at least one theme and that the names
+// are unique and well formed.
+func validateThemes(themes []Theme) error {
+	if len(themes) == 0 {
+		return fmt.Errorf("no themes defined")
+	}
+	seen := map[string]bool{}
+	for i, t := range themes {
+		switch {
+		case !themeNameRE.MatchString(t.Name):
+			return fmt.Errorf("theme %d %q: name must be lower case letters, digits and hyphens", i+1, t.Name)
+		case seen[t.Name]:
+			return fmt.Errorf("theme %d %q: duplicate name", i+1, t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return nil
+}
+
+// vars returns the CSS custom property declarations for t.
+func (t Theme) vars() string {
+	var b strings.Builder
+	for _, v := range [][2]string{
+		{"color-scheme", t.Scheme},
+		{"--background", t.Background},
+		{"--text", t.Text},
+		{"--muted", t.Muted},
+		{"--border", t.Border},
+		{"--input", t.Input},
+		{"--accent", t.Accent},
+		{"--connected", t.Connected},
+		{"--degraded", t.Degraded},
+		{"--offline", t.Offline},
+		{"--fail", t.Fail},
+	} {
+		if v[1] != "" {
+			fmt.Fprintf(&b, "\t\t%s: %s;\n", v[0], v[1])
+		}
+	}
+	return b.String()
+}
+
+// themeCSS returns the rules that set the custom properties for each theme.
+func themeCSS(themes []Theme) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\t:root {\n%s\t}\n", themes[0].vars())
+	for _, t := range themes[1:] {
+		if t.Scheme == "dark" {
+			fmt.Fprintf(&b, "\t@media (prefers-color-scheme: dark) {\n\t:root:not([data-theme]) {\n%s\t}\n\t}\n", t.vars())
+			break
+		}
+	}
+	for _, t := range themes {
+		fmt.Fprintf(&b, "\t:root[data-theme=%q] {\n%s\t}\n", t.Name, t.vars())
+	}
+	return b.String()
+}
+
+// ThemeJS returns the javascript for theme.js, which defines SetTheme(name).
+// SetTheme switches to the named theme and remembers the choice in
+// localStorage. An empty name goes back to the default. The script applies the
+// remembered theme as soon as it runs, so it is loaded in the head to avoid a
+// flash of the wrong colours, and it makes the theme picker call SetTheme.
+func ThemeJS(themes []Theme) string {
+	var names []string
+	for _, t := range themes {
+		names = append(names, fmt.Sprintf("%q", t.Name))
+	}
+	return fmt.Sprintf(`// Code generated by wasmskel/gen. DO NOT EDIT.
+	var themeNames = [%s];
+	function SetTheme(name) {
+		if (themeNames.indexOf(name) < 0) {
+			name = "";
+		}
+		try {
+			if (name) {
+				localStorage.setItem("theme", name);
+			} else {
+				localStorage.removeItem("theme");
+			}
+		} catch (e) {} // storage may be disabled
+		if (name) {
+			document.documentElement.setAttribute("data-theme", name);
+		} else {
+			document.documentElement.removeAttribute("data-theme");
+		}
+		var picker = document.getElementById("ThemeSelect");
+		if (picker) {
+			picker.value = name;
+		}
+	}
+	(function () {
+		var name = "";
+		try {
+			name = localStorage.getItem("theme") || "";
+		} catch (e) {}
+		if (themeNames.indexOf(name) >= 0) {
+			document.documentElement.setAttribute("data-theme", name);
+		}
+		document.addEventListener("DOMContentLoaded", function () {
+			var picker = document.getElementById("ThemeSelect");
+			if (picker) {
+				picker.addEventListener("change", function () {
+					SetTheme(picker.value);
+				});
+			}
+			SetTheme(name);
+		});
+	})();
+	`, strings.Join(names, ", "))
+}
+
+// label returns the English label of t, which is its Name if it has no
+// Label.
+func (t Theme) label() string {
+	if t.Label == "" {
+		return t.Name
+	}
+	return t.Label
+}
+
+// ThemePicker returns a div with a dropdown for choosing a theme. Its first
+// option follows the browser's preference. theme.js handles its changes.
+func ThemePicker(themes []Theme) *h.HtmlTree {
+	opts := []interface{}{h.Option(`value="" data-msg="system"`, msg("system"))}
+	for _, t := range themes {
+		label := html.EscapeString(t.label())
+		opts = append(opts, h.Option(fmt.Sprintf(`value="%s" data-title="%s"`, t.Name, label), label))
+	}
+	return h.Div(`class="THEME"`,
+		h.Label(`for="ThemeSelect" data-msg="theme"`, msg("theme")),
+		h.Select(`id="ThemeSelect" class="THEME"`, opts...))
+}
@@ -0,0 +1,200 @@
+package gen
+
+import (
+	"path"
+)
+
+// When Config.Worker is set the wasm client runs in a Web Worker, which has no
+// access to the page. The worker and the page exchange these messages:
+//
+//	page to worker:
+//	  {type: "setparm", seq, name, text}  set a parameter from its input's text
+//	  {type: "setter", seq, json}         set a parameter from a JSON request
+//	  {type: "route", hash}               show the view named by the URL fragment
+//	  {type: "restore", key, value}       a value the worker saved on an earlier visit
+//	  {type: "locale", tag}               write in the locale with this tag
+//	worker to page:
+//	  {type: "dom", id, prop, value}      assign a property of an element
+//	  {type: "state", changes}            parameters whose values changed
+//	  {type: "result", seq, ok, resp}     outcome of the set request seq
+//	  {type: "store", key, value}         save value under key in localStorage
+//	  {type: "fill"}                      set the input controls from the state
+//
+// The page script keeps a copy of the state from "state" messages so that it
+// can offer the same JavaScript API as the client does on the main thread.
+
+// workerJS starts the wasm client inside the worker. Messages that arrive
+// before the client has installed its own handler are queued, and the client
+// calls wasmReady to have them delivered.
+const workerJS = `// Code generated by wasmskel/gen. DO NOT EDIT.
+importScripts("/wasm_exec.js");
+const queued = [];
+self.onmessage = function (e) { queued.push(e); };
+self.wasmReady = function () {
+    queued.forEach(function (e) { self.onmessage(e); });
+    queued.length = 0;
+};
+const go = new Go();
+WebAssembly.instantiateStreaming(fetch("/app.wasm"), go.importObject).then((result) => {
+    go.run(result.instance);
+});
+`
+
+// genWorkerScript generates worker.js in cfg.AssetsDir
+func genWorkerScript(cfg Config) (f File) {
+	f.Path = path.Join(cfg.AssetsDir, "worker.js")
+	f.Data = []byte(workerJS)
+	return
+}
+
+// WorkerPageJS is the javascript for app.js in Worker mode. It starts the wasm
+// client in a Web Worker, applies the page updates it posts, tells it when the
+// route or the locale changes, keeps the values it saves, and defines Setter,
+// SetParm, GetParm, GetState, Subscribe and Unsubscribe in terms of messages
+// to and from the worker. It also wires the Set buttons and the keyboard
+// shortcuts of the input controls, which the client does itself on the main
+// thread.
+const WorkerPageJS = `// Code generated by wasmskel/gen. DO NOT EDIT.
+		// Start the wasm client in a Web Worker and act on its messages.
+		// Pass the query on so the worker sees options such as ?sim
+		const worker = new Worker("/worker.js" + location.search);
+		const pending = {}; // set requests awaiting results, by sequence number
+		const state = {};   // latest parameter values
+		const subs = {};    // subscriptions, by id
+		let seq = 0, subId = 0;
+		// fill sets input, the control for the named parameter, to the
+		// parameter's latest value, if there is one yet
+		function fill(input, name) {
+			if (!(name in state)) {
+				return;
+			}
+			if (input.type === "checkbox") {
+				input.checked = state[name] === true;
+			} else {
+				input.value = String(state[name]);
+			}
+		}
+		// notify calls sub's callback with those of values it asked for
+		function notify(sub, values) {
+			const mine = {};
+			let any = false;
+			for (const name in values) {
+				if (sub.names.length === 0 || sub.names.includes(name)) {
+					mine[name] = values[name];
+					any = true;
+				}
+			}
+			if (any) {
+				sub.callback(mine);
+			}
+		}
+		worker.onmessage = function (e) {
+			const m = e.data;
+			switch (m.type) {
+			case "dom":
+				const el = document.getElementById(m.id);
+				if (el) {
+					el[m.prop] = m.value;
+				}
+				break;
+			case "fill":
+				document.querySelectorAll("button[data-parm]").forEach(function (b) {
+					const input = document.getElementById(b.dataset.parm + "-input");
+					if (input) {
+						fill(input, b.dataset.parm);
+					}
+				});
+				break;
+			case "state":
+				Object.assign(state, m.changes);
+				for (const id in subs) {
+					notify(subs[id], m.changes);
+				}
+				break;
+			case "result":
+				const p = pending[m.seq];
+				delete pending[m.seq];
+				if (p) {
+					(m.ok ? p.resolve : p.reject)(m.resp);
+				}
+				break;
+			case "store":
+				try {
+					localStorage.setItem(m.key, m.value);
+				} catch (e) {} // storage may be disabled or full
+				break;
+			}
+		};
+		// Workers have no localStorage, so send the state the worker saved
+		// on an earlier visit for it to show until the server answers
+		try {
+			const saved = localStorage.getItem("lastState");
+			if (saved !== null) {
+				worker.postMessage({type: "restore", key: "lastState", value: saved});
+			}
+		} catch (e) {}
+		// The worker can't see the URL, so tell it which view to show
+		function route() {
+			worker.postMessage({type: "route", hash: location.hash});
+		}
+		addEventListener("hashchange", route);
+		route();
+		// or which locale to write in
+		function locale() {
+			worker.postMessage({type: "locale", tag: document.documentElement.lang});
+		}
+		addEventListener("localechange", locale);
+		locale();
+		function request(msg) {
+			return new Promise(function (resolve, reject) {
+				msg.seq = ++seq;
+				pending[msg.seq] = {resolve: resolve, reject: reject};
+				worker.postMessage(msg);
+			});
+		}
+		Setter = function (json) {
+			return request({type: "setter", json: json});
+		};
+		SetParm = function (name) {
+			const el = document.getElementById(name + "-input");
+			const text = el.type === "checkbox" ? String(el.checked) : el.value;
+			return request({type: "setparm", name: name, text: text});
+		};
+		GetParm = function (name) {
+			return state[name];
+		};
+		GetState = function () {
+			return Object.assign({}, state);
+		};
+		Subscribe = function (names, callback) {
+			const id = ++subId;
+			subs[id] = {names: typeof names === "string" ? [names] : names, callback: callback};
+			notify(subs[id], state);
+			return id;
+		};
+		Unsubscribe = function (id) {
+			delete subs[id];
+		};
+		// SetParm shows failures in the status table, so the controls
+		// needn't handle the rejection of the Promise it returns. In an
+		// input, Enter sets the parameter and Escape restores its value.
+		document.querySelectorAll("button[data-parm]").forEach(function (b) {
+			const name = b.dataset.parm;
+			b.addEventListener("click", function () {
+				SetParm(name).catch(function () {});
+			});
+			const input = document.getElementById(name + "-input");
+			if (!input) {
+				return;
+			}
+			input.addEventListener("keydown", function (e) {
+				if (e.key === "Enter") {
+					e.preventDefault();
+					SetParm(name).catch(function () {});
+				} else if (e.key === "Escape") {
+					e.preventDefault();
+					fill(input, name);
+				}
+			});
+		});
+`
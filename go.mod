@@ -5,7 +5,8 @@ go 1.16
 require (
 	github.com/Michael-F-Ellis/goht v1.1.1
 	github.com/Songmu/prompter v0.4.0
+	github.com/andybalholm/brotli v1.0.6
 	github.com/go-test/deep v1.0.7
 	github.com/magefile/mage v1.11.0
-	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110 // indirect
+	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110
 )
@@ -5,12 +5,17 @@ package main
 import (
 	"fmt"
 	"io/fs"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/Michael-F-Ellis/wasmskel/bundle"
+	"github.com/Michael-F-Ellis/wasmskel/gen"
 	"github.com/magefile/mage/mg"
 	"github.com/magefile/mage/sh"
 )
@@ -20,8 +25,10 @@ var Default = Build
 // Project directory tree. Values populated initPaths()
 var (
 	MageRoot   string // location of this file
+	ModName    string // go module name
 	GoRoot     string // path to go installation
 	AssetsPath string // assets subdir
+	DistPath   string // subdir for the assets as prepared for release
 	CommonPath string // common subdir
 	ServerPath string // server subdir
 	WasmPath   string // wasm subdir
@@ -39,8 +46,11 @@ func initPaths() {
 	must(err)
 	MageRoot, err = os.Getwd()
 	must(err)
+	ModName, err = sh.Output("go", "list", "-m")
+	must(err)
 	fmt.Println(MageRoot)
 	AssetsPath = path.Join(MageRoot, "server", "assets")
+	DistPath = path.Join(MageRoot, "server", "dist")
 	CommonPath = path.Join(MageRoot, "common")
 	ServerPath = path.Join(MageRoot, "server")
 	WasmPath = path.Join(MageRoot, "wasm")
@@ -56,19 +66,108 @@ func Generate() {
 		}
 	}
 	defer os.Chdir(MageRoot)
-	// Generate the common state struct
-	must(genState())
-	// Generate the web page
-	must(genIndexPage())
+	// Generate the state struct, web page, updater and dispatcher
+	must(gen.Generate(genConfig()))
 	// Install fresh copy of wasm_exec.js from go installation
-	must(sh.Run("cp", fmt.Sprintf("%s/misc/wasm/wasm_exec.js", GoRoot), AssetsPath))
-	// Generate the wasm client's updater function
-	must(genUpdater())
-	// Generate the server's dispatcher function
-	must(genDispatcher())
+	must(sh.Run("cp", goWasmExec(), AssetsPath))
 
 }
 
+// Verify regenerates the generated files into a temporary directory and
+// compares them with the copies in the tree. It prints a diff and fails if any
+// of them are stale, or if a file marked as generated in one of the output
+// directories is no longer generated at all, e.g. worker.js after Worker mode
+// is turned off.
+func Verify() (err error) {
+	initPaths()
+	files, err := gen.Files(genConfig())
+	if err != nil {
+		return
+	}
+	tmpDir, err := ioutil.TempDir("", "wasmskel-verify")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var stale []string
+	generated := map[string]bool{} // by path
+	dirs := map[string]bool{}
+	for _, f := range files {
+		generated[f.Path] = true
+		dirs[filepath.Dir(f.Path)] = true
+		rel, err := filepath.Rel(MageRoot, f.Path)
+		if err != nil {
+			return err
+		}
+		tmpPath := path.Join(tmpDir, filepath.Base(f.Path))
+		err = ioutil.WriteFile(tmpPath, f.Data, 0644)
+		if err != nil {
+			return err
+		}
+		// diff exits with status 1 when the files differ
+		_, err = sh.Exec(nil, os.Stdout, os.Stderr, "diff", "-u", "--label", rel, "--label", rel+" (regenerated)", rel, tmpPath)
+		if sh.ExitStatus(err) != 0 {
+			stale = append(stale, rel)
+		}
+	}
+	var orphans []string
+	for dir := range dirs {
+		marked, err := markedGenerated(dir)
+		if err != nil {
+			return err
+		}
+		for _, p := range marked {
+			if !generated[p] {
+				rel, _ := filepath.Rel(MageRoot, p)
+				orphans = append(orphans, rel)
+			}
+		}
+	}
+	sort.Strings(orphans)
+	var problems []string
+	if len(stale) > 0 {
+		problems = append(problems, "generated files are out of date, run mage generate: "+strings.Join(stale, ", "))
+	}
+	if len(orphans) > 0 {
+		problems = append(problems, "files are no longer generated, remove them: "+strings.Join(orphans, ", "))
+	}
+	if len(problems) > 0 {
+		err = fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return
+}
+
+// generatedMarker begins the comment gen puts near the top of every file it
+// generates.
+const generatedMarker = "Code generated by wasmskel/gen"
+
+// markedGenerated returns the paths of the files in dir that carry
+// generatedMarker in their first few lines.
+func markedGenerated(dir string) (paths []string, err error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.Mode().IsRegular() {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 512 {
+			data = data[:512]
+		}
+		if strings.Contains(string(data), generatedMarker) {
+			paths = append(paths, p)
+		}
+	}
+	return
+}
+
 // Build compiles the server and the Web Assembly client.
 func Build() {
 	mg.Deps(Generate)
@@ -82,8 +181,54 @@ func Build() {
 	must(os.Chdir(WasmPath))
 	must(sh.Run("env", "GOOS=js", "GOARCH=wasm", "go", "build", "-o", path.Join(AssetsPath, "app.wasm")))
 	// Build and install the server
-	must(os.Chdir(ServerPath))
-	must(sh.Run("go", "build", "-o", path.Join(MageRoot, "serve")))
+	must(buildServer())
+}
+
+// BuildTiny compiles the server, and compiles the Web Assembly client with
+// TinyGo, which makes a much smaller app.wasm. TinyGo's wasm_exec.js replaces
+// the one from the go installation.
+func BuildTiny() {
+	mg.Deps(Generate)
+	must := func(_err error) {
+		if _err != nil {
+			log.Fatal(_err)
+		}
+	}
+	defer os.Chdir(MageRoot)
+	// Install TinyGo's wasm_exec.js, which must match the compiler
+	tinyRoot, err := sh.Output("tinygo", "env", "TINYGOROOT")
+	must(err)
+	must(sh.Run("cp", path.Join(tinyRoot, "targets", "wasm_exec.js"), AssetsPath))
+	// Build and install the WASM
+	must(os.Chdir(WasmPath))
+	must(sh.Run("tinygo", "build", "-o", path.Join(AssetsPath, "app.wasm"), "-target", "wasm", "-no-debug", "."))
+	// Build and install the server
+	must(buildServer())
+}
+
+// buildServer prepares the current assets for release and compiles the
+// server, embedding them. See package bundle.
+func buildServer() (err error) {
+	_, err = bundle.Bundle(AssetsPath, DistPath)
+	if err != nil {
+		return
+	}
+	err = os.Chdir(ServerPath)
+	if err != nil {
+		return
+	}
+	err = sh.Run("go", "build", "-tags", "dist", "-o", path.Join(MageRoot, "serve"))
+	return
+}
+
+// goWasmExec returns the path of wasm_exec.js in the go installation. Go 1.24
+// moved it from misc/wasm to lib/wasm.
+func goWasmExec() string {
+	fpath := path.Join(GoRoot, "lib", "wasm", "wasm_exec.js")
+	if _, err := os.Stat(fpath); err == nil {
+		return fpath
+	}
+	return path.Join(GoRoot, "misc", "wasm", "wasm_exec.js")
 }
 
 // Run builds and executes the server
@@ -105,9 +250,18 @@ func Clean() {
 	check(os.Remove(path.Join(AssetsPath, "app.wasm")))
 	check(os.Remove(path.Join(AssetsPath, "wasm_exec.js")))
 	check(os.Remove(path.Join(AssetsPath, "index.html")))
+	check(os.Remove(path.Join(AssetsPath, "style.css")))
+	check(os.Remove(path.Join(AssetsPath, "theme.js")))
+	check(os.Remove(path.Join(AssetsPath, "i18n.js")))
+	check(os.Remove(path.Join(AssetsPath, "app.js")))
+	check(os.Remove(path.Join(AssetsPath, "manifest.webmanifest")))
+	check(os.Remove(path.Join(AssetsPath, "icon.svg")))
+	check(os.Remove(path.Join(AssetsPath, "sw.js")))
+	check(os.Remove(path.Join(AssetsPath, "worker.js")))
+	check(os.RemoveAll(DistPath))
 
-	// Other generated files have names ending "_g.*"
-	re := regexp.MustCompile(`_g\.\S+$`) // the pattern to match
+	// Other generated files have names ending "_g.*" or "_g_test.go"
+	re := regexp.MustCompile(`_g(_test)?\.\S+$`) // the pattern to match
 
 	// Walk the directory tree and remove them
 	var walker filepath.WalkFunc = func(path string, info fs.FileInfo, err error) error {
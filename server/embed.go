@@ -0,0 +1,12 @@
+// +build !dist
+
+package main
+
+import "embed"
+
+// The assets directory contains static files served by the application.
+//go:embed assets
+var assets embed.FS
+
+// assetsDir is the directory in assets that holds the files to serve.
+const assetsDir = "assets"
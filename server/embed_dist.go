@@ -0,0 +1,14 @@
+// +build dist
+
+package main
+
+import "embed"
+
+// The dist directory contains the static files served by the application as
+// prepared for release by the bundle package. The mage build creates it and
+// compiles the server with -tags dist.
+//go:embed dist
+var assets embed.FS
+
+// assetsDir is the directory in assets that holds the files to serve.
+const assetsDir = "dist"
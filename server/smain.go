@@ -1,7 +1,6 @@
 package main
 
 import (
-	"embed"
 	"encoding/json"
 	"fmt"
 	"io/fs"
@@ -16,10 +15,6 @@ import (
 // This is the global state that is shared via a JSON API.
 var State = common.State{}
 
-// The assets directory contains static files served by the application.
-//go:embed assets
-var assets embed.FS
-
 // Main launches a goroutine that continually updates the global state. Then it
 // defines the allowed http requests and enters a ListenAndServe loop.
 func main() {
@@ -27,7 +22,7 @@ func main() {
 	mux := http.NewServeMux()
 	// fs.Sub returns a file system rooted under our embedded assets directory
 	// so that a request for, say, "/app.wasm" returns the file in "assets/app.wasm"
-	assetSys, err := fs.Sub(assets, "assets")
+	assetSys, err := fs.Sub(assets, assetsDir)
 	if err != nil {
 		panic("failed to create sub-tree of assets") // should never happen
 	}
@@ -36,9 +31,18 @@ func main() {
 	mux.HandleFunc("/get", getRequestHandler)
 	mux.HandleFunc("/set", setRequestHandler)
 	// The following creates a handler for static file requests.
-	mux.Handle("/", http.FileServer(http.FS(assetSys)))
+	mux.Handle("/", staticHandler(assetSys))
 	// Launch the http service
-	log.Fatal(http.ListenAndServe(":9090", mux))
+	log.Fatal(http.ListenAndServe(":9090", withPolicy(mux)))
+}
+
+// withPolicy returns a handler that adds the page's Content-Security-Policy
+// to every response from next.
+func withPolicy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		next.ServeHTTP(w, r)
+	})
 }
 
 // getRequestHandler sends the global state in JSON encoded format.
@@ -74,20 +78,16 @@ func fail(w http.ResponseWriter, msg string, status int) {
 // State that are part of the JSON API.
 func GetJSON(sp *common.State) (jsn []byte, err error) {
 	mpcopy := sp.Get()
-	jsn, err = json.Marshal(mpcopy)
+	jsn, err = mpcopy.MarshalJSON()
 	return
 }
 
 // Updater continually changes State, simulating
 // an arbitrary back-end process.
 func Updater() {
-	f := func(p *common.State) {
-		p.Alpha += 1
-		p.Beta += 2
-	}
 	for {
-		time.Sleep(time.Second)
-		State.DirectUpdate(f)
+		time.Sleep(common.SimInterval)
+		State.DirectUpdate(common.SimStep)
 	}
 }
 
@@ -103,8 +103,10 @@ func setRequestHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	r.Body.Close()
 	// Extract the json to a map of RawMessage values
-	// to allow piecemeal unmarshalling of fields
-	var objmap map[string]*json.RawMessage
+	// to allow piecemeal unmarshalling of fields. A null
+	// value is kept as the RawMessage "null", for the
+	// Dispatcher to reject.
+	var objmap map[string]json.RawMessage
 	err = json.Unmarshal(jsn, &objmap)
 	if err != nil {
 		fail(w, err.Error(), http.StatusBadRequest)
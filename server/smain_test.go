@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetRequestHandler(t *testing.T) {
+	tests := []struct {
+		body   string
+		status int
+		errMsg string // in the response, or "" for success
+	}{
+		{`{"Gamma":42}`, http.StatusOK, ""},
+		{`{"Alpha":null}`, http.StatusBadRequest, UnsettableErr("Alpha").Error()},
+		{`{"Gamma":null}`, http.StatusBadRequest, "null is not a valid value for Gamma"},
+		{`{"Gamma":1,"Zeta":2}`, http.StatusBadRequest, "only one item per set request"},
+		{`[1]`, http.StatusBadRequest, "cannot unmarshal"},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		setRequestHandler(w, httptest.NewRequest("POST", "/set", strings.NewReader(tc.body)))
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.body, tc.status, w.Code)
+		}
+		body := w.Body.String()
+		switch {
+		case tc.errMsg == "" && body != `{"Err":null}`:
+			t.Errorf("%s: expected success, got %s", tc.body, body)
+		case tc.errMsg != "" && !strings.Contains(body, tc.errMsg):
+			t.Errorf("%s: expected the response to contain %q, got %s", tc.body, tc.errMsg, body)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hashedRE matches the names the bundle package gives assets, which include
+// a hash of their content, e.g. "app.0123456789ab.wasm".
+var hashedRE = regexp.MustCompile(`\.[0-9a-f]{12}\.[^.]+$`)
+
+func init() {
+	// The web app manifest's type isn't in the standard library's table
+	_ = mime.AddExtensionType(".webmanifest", "application/manifest+json")
+}
+
+// encodings are the content codings of the precompressed variants that the
+// bundle package writes, in order of preference, with their file extensions.
+var encodings = []struct{ coding, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// staticHandler returns a handler that serves the files in fsys. Hashed
+// assets that exist may be cached forever, and everything else, 404s
+// included, must be revalidated. If
+// the client accepts an encoding that a file has a precompressed variant for,
+// the variant is sent instead.
+func staticHandler(fsys fs.FS) http.Handler {
+	files := http.FileServer(http.FS(fsys))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			name = "index.html"
+		}
+		// Only a hashed asset that exists may be cached forever. A 404 for
+		// one that hasn't been deployed yet must not outlive the deployment.
+		cache := func(found bool) {
+			if found && hashedRE.MatchString(name) {
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			} else {
+				w.Header().Set("Cache-Control", "no-cache")
+			}
+		}
+		for _, enc := range encodings {
+			f, err := fsys.Open(name + enc.ext)
+			if err != nil {
+				continue
+			}
+			defer f.Close()
+			w.Header().Set("Vary", "Accept-Encoding")
+			content, ok := f.(io.ReadSeeker)
+			if !ok || !accepts(r.Header.Get("Accept-Encoding"), enc.coding) {
+				continue
+			}
+			cache(true)
+			w.Header().Set("Content-Encoding", enc.coding)
+			w.Header().Set("Content-Type", contentType(name))
+			// Embedded files have no modification time. Hashed assets never
+			// change and the rest are small, so none is needed.
+			http.ServeContent(w, r, name, time.Time{}, content)
+			return
+		}
+		_, err := fs.Stat(fsys, name)
+		cache(err == nil)
+		files.ServeHTTP(w, r)
+	})
+}
+
+// contentType returns the media type of the named file from its extension.
+func contentType(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// accepts reports whether an Accept-Encoding header value allows coding with
+// a non-zero quality, naming it or else matching it with "*".
+func accepts(header, coding string) bool {
+	q, star := -1.0, -1.0
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(item, ";")
+		name := strings.TrimSpace(parts[0])
+		v := 1.0
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if f, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					v = f
+				}
+			}
+		}
+		switch {
+		case strings.EqualFold(name, coding):
+			q = v
+		case name == "*":
+			star = v
+		}
+	}
+	if q < 0 {
+		q = star
+	}
+	return q > 0
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":               {Data: []byte("<p>page")},
+		"index.html.gz":            {Data: []byte("gzipped page")},
+		"app.0123456789ab.wasm":    {Data: []byte("wasm")},
+		"app.0123456789ab.wasm.br": {Data: []byte("brotli wasm")},
+		"app.0123456789ab.wasm.gz": {Data: []byte("gzipped wasm")},
+		"style.0123456789ab.css":   {Data: []byte("css")},
+		"notes.txt":                {Data: []byte("notes")},
+	}
+	tests := []struct {
+		path, accept     string
+		status           int
+		encoding, body   string
+		cacheControl     string
+		contentType, vry string
+	}{
+		{"/", "gzip", http.StatusOK, "gzip", "gzipped page", "no-cache", "text/html; charset=utf-8", "Accept-Encoding"},
+		{"/", "", http.StatusOK, "", "<p>page", "no-cache", "text/html; charset=utf-8", "Accept-Encoding"},
+		{"/app.0123456789ab.wasm", "gzip, deflate, br", http.StatusOK, "br", "brotli wasm", "public, max-age=31536000, immutable", "application/wasm", "Accept-Encoding"},
+		{"/app.0123456789ab.wasm", "br;q=0, gzip", http.StatusOK, "gzip", "gzipped wasm", "public, max-age=31536000, immutable", "application/wasm", "Accept-Encoding"},
+		{"/app.0123456789ab.wasm", "identity", http.StatusOK, "", "wasm", "public, max-age=31536000, immutable", "application/wasm", "Accept-Encoding"},
+		{"/style.0123456789ab.css", "br", http.StatusOK, "", "css", "public, max-age=31536000, immutable", "text/css; charset=utf-8", ""},
+		{"/notes.txt", "*", http.StatusOK, "", "notes", "no-cache", "text/plain; charset=utf-8", ""},
+		// A hashed asset that isn't there yet mustn't be cached
+		{"/app.fedcba987654.wasm", "br", http.StatusNotFound, "", "404 page not found\n", "no-cache", "text/plain; charset=utf-8", ""},
+	}
+	handler := staticHandler(fsys)
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", test.path, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept-Encoding", test.accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		got := w.Result()
+		where := test.path + " with " + test.accept
+		if got.StatusCode != test.status {
+			t.Errorf("%s: expected status %d, got %d", where, test.status, got.StatusCode)
+		}
+		for header, want := range map[string]string{
+			"Content-Encoding": test.encoding,
+			"Cache-Control":    test.cacheControl,
+			"Content-Type":     test.contentType,
+			"Vary":             test.vry,
+		} {
+			if got.Header.Get(header) != want {
+				t.Errorf("%s: expected %s %q, got %q", where, header, want, got.Header.Get(header))
+			}
+		}
+		if w.Body.String() != test.body {
+			t.Errorf("%s: expected body %q, got %q", where, test.body, w.Body.String())
+		}
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	tests := []struct {
+		header, coding string
+		want           bool
+	}{
+		{"", "gzip", false},
+		{"gzip", "gzip", true},
+		{"GZIP", "gzip", true},
+		{"deflate, gzip;q=0.5", "gzip", true},
+		{"gzip;q=0", "gzip", false},
+		{"*", "br", true},
+		{"*, br;q=0", "br", false},
+		{"gzip;q=0, *", "gzip", false},
+		{"br", "gzip", false},
+	}
+	for _, test := range tests {
+		if got := accepts(test.header, test.coding); got != test.want {
+			t.Errorf("accepts(%q, %q) = %v, want %v", test.header, test.coding, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// stateMap returns the fields of s keyed by parameter name, with the values
+// JSON would give them, so the result converts directly to a JS object.
+func stateMap(s *common.State) (m map[string]interface{}) {
+	jsn, err := s.MarshalJSON()
+	if err == nil {
+		m, err = common.DecodeObject(jsn)
+	}
+	if err != nil { // should never happen with a generated State
+		panic(err)
+	}
+	return
+}
+
+// subscription is a callback that wants to hear about changes to the named
+// parameters, or to all parameters if names is empty.
+type subscription struct {
+	names  map[string]bool
+	notify func(changes map[string]interface{})
+}
+
+// filter returns the values in values of the parameters s wants to hear
+// about.
+func (s *subscription) filter(values map[string]interface{}) map[string]interface{} {
+	mine := map[string]interface{}{}
+	for name, v := range values {
+		if len(s.names) == 0 || s.names[name] {
+			mine[name] = v
+		}
+	}
+	return mine
+}
+
+// subscriberList holds the subscriptions made through the JS API and the
+// values they were last told about.
+type subscriberList struct {
+	mu   sync.Mutex
+	next int
+	subs map[int]*subscription
+	last map[string]interface{} // nil until the first publish
+}
+
+// subscribers receives every state fetched from the server.
+var subscribers = &subscriberList{subs: map[int]*subscription{}}
+
+// add registers notify to be called with the changed values whenever any of
+// the named parameters change. If a state has been published, notify is first
+// called with its current values of those parameters. It returns an id for
+// remove.
+func (l *subscriberList) add(names []string, notify func(changes map[string]interface{})) (id int) {
+	l.mu.Lock()
+	sub := &subscription{names: map[string]bool{}, notify: notify}
+	for _, name := range names {
+		sub.names[name] = true
+	}
+	l.next++
+	id = l.next
+	l.subs[id] = sub
+	mine := sub.filter(l.last)
+	l.mu.Unlock()
+	// Call outside the lock so the callback may subscribe or unsubscribe.
+	if len(mine) > 0 {
+		notify(mine)
+	}
+	return
+}
+
+// remove cancels the subscription with the given id.
+func (l *subscriberList) remove(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.subs, id)
+}
+
+// publish compares cur with the values last published and notifies each
+// subscriber of the changes it asked for. Everything counts as changed the
+// first time.
+func (l *subscriberList) publish(cur map[string]interface{}) {
+	l.mu.Lock()
+	changed := map[string]interface{}{}
+	for name, v := range cur {
+		if old, ok := l.last[name]; !ok || old != v {
+			changed[name] = v
+		}
+	}
+	l.last = cur
+	var calls []func()
+	for _, sub := range l.subs {
+		if mine := sub.filter(changed); len(mine) > 0 {
+			notify := sub.notify
+			calls = append(calls, func() { notify(mine) })
+		}
+	}
+	l.mu.Unlock()
+	// Call outside the lock so callbacks may subscribe or unsubscribe.
+	for _, call := range calls {
+		call()
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestSubscribers(t *testing.T) {
+	l := &subscriberList{subs: map[int]*subscription{}}
+	var all, alpha []map[string]interface{}
+	l.add(nil, func(c map[string]interface{}) { all = append(all, c) })
+	id := l.add([]string{"Alpha"}, func(c map[string]interface{}) { alpha = append(alpha, c) })
+
+	l.publish(map[string]interface{}{"Alpha": 1.0, "Beta": 2.0}) // first time, all changed
+	l.publish(map[string]interface{}{"Alpha": 1.0, "Beta": 3.0}) // Beta changed
+	l.remove(id)
+	l.publish(map[string]interface{}{"Alpha": 4.0, "Beta": 3.0}) // Alpha changed
+
+	expectAll := []map[string]interface{}{
+		{"Alpha": 1.0, "Beta": 2.0},
+		{"Beta": 3.0},
+		{"Alpha": 4.0},
+	}
+	if diff := deep.Equal(all, expectAll); diff != nil {
+		t.Errorf("%v", diff)
+	}
+	expectAlpha := []map[string]interface{}{{"Alpha": 1.0}}
+	if diff := deep.Equal(alpha, expectAlpha); diff != nil {
+		t.Errorf("%v", diff)
+	}
+}
+
+func TestLateSubscriber(t *testing.T) {
+	l := &subscriberList{subs: map[int]*subscription{}}
+	var before []map[string]interface{}
+	l.add([]string{"Alpha"}, func(c map[string]interface{}) { before = append(before, c) })
+	if len(before) != 0 {
+		t.Errorf("expected no call before the first publish, got %v", before)
+	}
+	l.publish(map[string]interface{}{"Alpha": 1.0, "Beta": 2.0})
+
+	var late []map[string]interface{}
+	l.add([]string{"Beta"}, func(c map[string]interface{}) { late = append(late, c) })
+	l.publish(map[string]interface{}{"Alpha": 1.0, "Beta": 3.0})
+
+	expect := []map[string]interface{}{{"Beta": 2.0}, {"Beta": 3.0}}
+	if diff := deep.Equal(late, expect); diff != nil {
+		t.Errorf("%v", diff)
+	}
+}
+
+func TestStateMap(t *testing.T) {
+	m := stateMap(&State)
+	if _, ok := m["Alpha"]; !ok {
+		t.Errorf("expected stateMap to hold Alpha, got %v", m)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// HTTP status codes the client cares about
+const (
+	statusOK         = 200
+	statusBadRequest = 400
+)
+
+// serverURL is prefixed to request paths. It is empty in the browser, where
+// paths are relative to the page, and set by tests to a local test server.
+var serverURL = ""
+
+// Result describes the outcome of one request to the server.
+type Result struct {
+	Status    string        // HTTP status, e.g. "200 OK", empty if no response arrived
+	Code      int           // HTTP status code, 0 if no response arrived
+	Latency   time.Duration // time from sending the request to reading the response
+	ServerErr string        // Err member of the server's JSON response, if any
+	Body      []byte        // the response body
+	Err       error         // nil if the request succeeded
+}
+
+// String summarizes r for the logs view, e.g. "200 OK in 12ms" or
+// "400 Bad Request in 8ms: Gamma must be between 0 and 100, got 101".
+func (r Result) String() string {
+	return r.summary(true)
+}
+
+// Outcome summarizes r for the status table, which shows the latency
+// separately, e.g. "200 OK" or "400 Bad Request: Gamma must be between 0 and
+// 100, got 101".
+func (r Result) Outcome() string {
+	return r.summary(false)
+}
+
+// summary summarizes r, with its latency if latency is true.
+func (r Result) summary(latency bool) string {
+	var s string
+	switch {
+	case r.Code == 0 && r.Err != nil:
+		return r.Err.Error() // no response, nothing else to report
+	case r.Code == 0:
+		return "no request sent"
+	case latency:
+		s = fmt.Sprintf("%s in %v", r.Status, r.Latency.Round(time.Millisecond))
+	default:
+		s = r.Status
+	}
+	switch {
+	case r.ServerErr != "":
+		s += ": " + r.ServerErr
+	case r.Err != nil:
+		s += ": " + r.Err.Error()
+	}
+	return s
+}
+
+// statusLine returns the Status for a response with the given code and status
+// text, e.g. "200 OK", or just "200" if the text is empty, as it is over
+// HTTP/2.
+func statusLine(code int, text string) string {
+	if text == "" {
+		return strconv.Itoa(code)
+	}
+	return strconv.Itoa(code) + " " + text
+}
+
+// checkResponse fills in r.ServerErr from the response body and sets r.Err
+// if the status is anything other than 200.
+func checkResponse(r *Result) {
+	// Error responses carry {"Err": "message"}. Successful sets carry
+	// {"Err": null} and gets carry the state, so there is nothing to find.
+	if resp, err := common.DecodeObject(r.Body); err == nil {
+		if msg, ok := resp["Err"].(string); ok {
+			r.ServerErr = msg
+		}
+	}
+	if r.Code != statusOK {
+		r.Err = fmt.Errorf("%s: %s", r.Status, r.Body)
+		if r.ServerErr != "" {
+			r.Err = fmt.Errorf("%s: %s", r.Status, r.ServerErr)
+		}
+	}
+}
+
+// Transport carries the client's requests to the server, or to whatever
+// stands in for it, and reports their outcomes.
+type Transport interface {
+	Get() Result                // fetch the state as JSON
+	Set(jsonData []byte) Result // post a /set request
+}
+
+// transport is the Transport the client uses. main may replace it.
+var transport Transport = newServerTransport()
+
+// getStateFromServer fetches the current values in State through transport
+// and updates the local copy.
+func getStateFromServer() (r Result) {
+	r = transport.Get()
+	if r.Err != nil {
+		return
+	}
+	// Decode the response and update the global state
+	mp := &common.State{}
+	err := mp.UnmarshalJSON(r.Body)
+	if err != nil {
+		r.Err = fmt.Errorf("couldn't decode state: %v", err)
+		return
+	}
+	SP.DirectUpdate(func(p *common.State) { *p = *mp })
+	return
+}
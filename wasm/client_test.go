@@ -0,0 +1,179 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// testServer starts a server that answers /get and /set with the given
+// status and body, and points the client at it.
+func testServer(t *testing.T, status int, body string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		serverURL = ""
+	})
+	serverURL = srv.URL
+}
+
+func TestPollState(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	testServer(t, http.StatusOK, `{"Alpha": 1.5, "Count": 3, "Mode": "Manual"}`)
+	pollState(newLink())
+	fake.expect(t, "Alpha", "textContent", "1.50")
+	fake.expect(t, "Count", "innerHTML", barSVG(3, 0, 10, "3"))
+	fake.expect(t, "Mode", "textContent", "Manual")
+	fake.expect(t, "GetMsg", "className", "STATUS OK")
+	fake.expect(t, "LinkStatus", "textContent", "connected")
+	fake.expect(t, "ParmTable", "className", "PARM")
+}
+
+func TestPollStateFailure(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	testServer(t, http.StatusInternalServerError, `{"Err": "boom"}`)
+	lnk := newLink()
+	for i, want := range []string{"degraded", "degraded", "offline"} {
+		pollState(lnk)
+		fake.expect(t, "LinkStatus", "textContent", want)
+		if i == 0 {
+			fake.expect(t, "GetMsg", "textContent", "500 Internal Server Error: boom")
+			if latency, _ := fake.Get("GetMsg-time", "textContent"); !strings.HasSuffix(latency, "s") {
+				t.Errorf("expected GetMsg-time to show the latency, got %q", latency)
+			}
+		}
+	}
+	fake.expect(t, "GetMsg", "className", "STATUS FAIL")
+	fake.expect(t, "ParmTable", "className", "PARM STALE")
+	logs, _ := fake.Get("LogList", "innerHTML")
+	if !strings.Contains(logs, " link offline</li>") || strings.Count(logs, " link degraded</li>") != 1 {
+		t.Errorf("expected the logs view to show each change of link state once, got %q", logs)
+	}
+}
+
+func TestSendSet(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	testServer(t, http.StatusBadRequest, `{"Err": "no good"}`)
+	var outcomes []string
+	q := newSetQueue()
+	for _, v := range []string{"1", "2"} {
+		q.push("Gamma", []byte(`{"Gamma":`+v+`}`), func(resp map[string]interface{}, err error) {
+			outcomes = append(outcomes, resp["Err"].(string))
+		})
+	}
+	r := q.pop()
+	if string(r.jsonData) != `{"Gamma":2}` || q.pop() != nil {
+		t.Fatalf("expected sets of Gamma to coalesce to the last value")
+	}
+	sendSet(r)
+	if len(outcomes) != 2 || outcomes[0] != "no good" || outcomes[1] != "no good" {
+		t.Errorf("expected both callers to get the server's Err, got %v", outcomes)
+	}
+	fake.expect(t, "SetMsg", "className", "STATUS FAIL")
+}
+
+func TestLinkDelay(t *testing.T) {
+	lnk := newLink()
+	if d := lnk.delay(); d != pollInterval {
+		t.Errorf("expected %v while connected, got %v", pollInterval, d)
+	}
+	for i := 1; i <= 10; i++ {
+		lnk.record(false, time.Now())
+		max := pollInterval << uint(i)
+		if max > maxBackoff {
+			max = maxBackoff
+		}
+		if d := lnk.delay(); d < max/2 || d > max {
+			t.Errorf("after %d failures expected delay in [%v, %v], got %v", i, max/2, max, d)
+		}
+	}
+}
+
+func TestPollStateQuiet(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	testServer(t, http.StatusOK, `{"Alpha": 1.5}`)
+	lnk := newLink()
+	pollState(lnk)
+	fake.expect(t, "GetMsg", "textContent", "200 OK")
+	// Screen readers announce every change to GetMsg, so polls with the same
+	// outcome only update the latency beside it.
+	fake.Set("GetMsg", "textContent", "unchanged")
+	fake.Set("GetMsg-time", "textContent", "")
+	pollState(lnk)
+	fake.expect(t, "GetMsg", "textContent", "unchanged")
+	if latency, _ := fake.Get("GetMsg-time", "textContent"); latency == "" {
+		t.Errorf("expected GetMsg-time to show the latency")
+	}
+}
+
+func TestLiveRegionsQuiet(t *testing.T) {
+	// Screen readers announce every write to LinkStatus and OfflineBanner,
+	// so polls with the same outcome mustn't touch them.
+	regions := []string{"LinkStatus.textContent", "LinkStatus.className", "OfflineBanner.className"}
+	fake := newFakeDOM(t)
+	dom = fake
+	testServer(t, http.StatusOK, `{"Alpha": 1.5}`)
+	lnk := newLink()
+	pollState(lnk)
+	pollState(lnk)
+	for _, r := range regions {
+		if n := fake.writes[r]; n != 1 {
+			t.Errorf("connected: %s written %d times, want 1", r, n)
+		}
+	}
+
+	// Once offline, the banner stays put too.
+	testServer(t, http.StatusInternalServerError, `{"Err": "down"}`)
+	for i := 0; i < offlineAfter; i++ {
+		pollState(lnk)
+	}
+	fake.writes = map[string]int{}
+	pollState(lnk)
+	pollState(lnk)
+	for _, r := range append(regions, "OfflineBanner.textContent") {
+		if n := fake.writes[r]; n != 0 {
+			t.Errorf("offline: %s written %d times, want 0", r, n)
+		}
+	}
+}
+
+func TestFillInputs(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	defer SP.DirectUpdate(func(p *common.State) { *p = common.State{} })
+	// A fresh page starts its controls at the server's values, not the
+	// browser's defaults, so that Set doesn't send those.
+	testServer(t, http.StatusOK, `{"Enabled": true, "Mode": "Manual", "Gamma": 12.5}`)
+	lnk := newLink()
+	pollState(lnk)
+	fake.expect(t, "Enabled-input", "checked", "true")
+	fake.expect(t, "Mode-input", "value", "Manual")
+	fake.expect(t, "Gamma-input", "value", "12.5")
+	// Later polls leave them to the user.
+	fake.Set("Mode-input", "value", "Off")
+	testServer(t, http.StatusOK, `{"Enabled": false, "Mode": "Auto"}`)
+	pollState(lnk)
+	fake.expect(t, "Mode-input", "value", "Off")
+}
+
+func TestStatusLine(t *testing.T) {
+	if s := statusLine(200, "OK"); s != "200 OK" {
+		t.Errorf("expected %q, got %q", "200 OK", s)
+	}
+	// HTTP/2 responses have no status text.
+	if s := statusLine(200, ""); s != "200" {
+		t.Errorf("expected %q, got %q", "200", s)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// DOM is the part of the web page the client reads and writes. Elements are
+// found by id, and their properties, e.g. "textContent" or "checked", are
+// read and written as strings. In the browser it is the page's document; tests
+// substitute an in-memory fake.
+type DOM interface {
+	Get(id, prop string) (value string, err error)
+	Set(id, prop, value string) error
+}
+
+// dom is the page the client updates. main sets it.
+var dom DOM
+
+// fillInputs starts the parameters' input controls at their values in State.
+// A Web Worker can't see the controls, so there main has the page script do
+// it instead.
+var fillInputs = FillParmInputs
+
+// setElementAttributeById assigns a string value to a DOM element
+// with the given id.
+func setElementAttributeById(id, attr, value string) (err error) {
+	err = dom.Set(id, attr, value)
+	if err != nil {
+		fmt.Println(err)
+	}
+	return
+}
+
+// readParmInput reads the input control for the named parameter, checks its
+// value against the schema and returns the JSON for a /set request.
+func readParmInput(name string) (jsonData []byte, err error) {
+	id := name + "-input"
+	prop := "value"
+	typ, err := dom.Get(id, "type")
+	if err != nil {
+		return
+	}
+	if typ == "checkbox" {
+		prop = "checked"
+	}
+	text, err := dom.Get(id, prop)
+	if err != nil {
+		return
+	}
+	jsonData, err = parmRequest(name, text)
+	return
+}
+
+// parmRequest checks text, as read from the input control for the named
+// parameter, against the schema and returns the JSON for a /set request.
+func parmRequest(name, text string) (jsonData []byte, err error) {
+	value, err := common.ParseParm(name, text)
+	if err != nil {
+		return
+	}
+	jsonData, err = common.EncodeObject(map[string]interface{}{name: value})
+	return
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+// fakeDOM is an in-memory DOM. An element exists if its id is a key of props.
+type fakeDOM struct {
+	mu     sync.Mutex
+	props  map[string]map[string]string // id -> property -> value
+	writes map[string]int               // number of Sets, by "id.prop"
+}
+
+// newFakeDOM returns a fakeDOM holding an element for each id attribute in the
+// generated index page.
+func newFakeDOM(t *testing.T) *fakeDOM {
+	page, err := ioutil.ReadFile("../server/assets/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeDOM{props: map[string]map[string]string{}, writes: map[string]int{}}
+	for _, m := range regexp.MustCompile(`\sid="([^"]+)"`).FindAllSubmatch(page, -1) {
+		d.props[string(m[1])] = map[string]string{}
+	}
+	return d
+}
+
+func (d *fakeDOM) Get(id, prop string) (value string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	el, ok := d.props[id]
+	if !ok {
+		err = fmt.Errorf("Unable to get element with id %s", id)
+		return
+	}
+	value = el[prop]
+	return
+}
+
+func (d *fakeDOM) Set(id, prop, value string) (err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	el, ok := d.props[id]
+	if !ok {
+		err = fmt.Errorf("Unable to get element with id %s", id)
+		return
+	}
+	el[prop] = value
+	d.writes[id+"."+prop]++
+	return
+}
+
+// expect reports an error if the property of element id doesn't hold want.
+func (d *fakeDOM) expect(t *testing.T, id, prop, want string) {
+	t.Helper()
+	got, err := d.Get(id, prop)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got != want {
+		t.Errorf("%s.%s: expected %q, got %q", id, prop, want, got)
+	}
+}
+
+func TestReadParmInput(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	fake.Set("Enabled-input", "type", "checkbox")
+	fake.Set("Enabled-input", "checked", "true")
+	jsonData, err := readParmInput("Enabled")
+	if err != nil || string(jsonData) != `{"Enabled":true}` {
+		t.Errorf("expected {\"Enabled\":true}, got %s, %v", jsonData, err)
+	}
+	fake.Set("Gamma-input", "type", "range")
+	fake.Set("Gamma-input", "value", "101")
+	if _, err = readParmInput("Gamma"); err == nil {
+		t.Errorf("expected out of range Gamma to be rejected")
+	}
+	if _, err = readParmInput("Alpha"); err == nil {
+		t.Errorf("expected Alpha, which has no input, to be rejected")
+	}
+}
@@ -0,0 +1,81 @@
+// +build tinygo
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+	"time"
+)
+
+// newServerTransport returns the Transport for talking to the server.
+func newServerTransport() Transport {
+	return fetchTransport{}
+}
+
+// fetchTransport is a Transport that calls the browser's fetch API directly.
+// TinyGo builds use it because TinyGo's net/http support is limited.
+type fetchTransport struct{}
+
+// Get sends a /get request to the server.
+func (fetchTransport) Get() Result {
+	return fetch("GET", serverURL+"/get", nil, 500*time.Millisecond)
+}
+
+// Set posts a /set request to the server to change the value of a
+// parameter.
+func (fetchTransport) Set(jsonData []byte) Result {
+	return fetch("POST", serverURL+"/set", jsonData, 2*time.Second)
+}
+
+// fetch makes a request with the fetch API, aborting it after timeout, and
+// reads the response into a Result. It blocks until the request completes, so
+// it must not be called from a javascript callback.
+func fetch(method, url string, body []byte, timeout time.Duration) (r Result) {
+	ctrl := js.Global().Get("AbortController").New()
+	timer := time.AfterFunc(timeout, func() { ctrl.Call("abort") })
+	defer timer.Stop()
+	opts := map[string]interface{}{"method": method, "signal": ctrl.Get("signal")}
+	if body != nil {
+		opts["body"] = string(body)
+		opts["headers"] = map[string]interface{}{"Content-Type": "application/json"}
+	}
+	start := time.Now()
+	resp, err := await(js.Global().Call("fetch", url, opts))
+	if err != nil {
+		r.Err = err
+		return
+	}
+	text, err := await(resp.Call("text"))
+	r.Latency = time.Since(start)
+	r.Code = resp.Get("status").Int()
+	r.Status = statusLine(r.Code, resp.Get("statusText").String())
+	if err != nil {
+		r.Err = err
+		return
+	}
+	r.Body = []byte(text.String())
+	checkResponse(&r)
+	return
+}
+
+// await blocks until the javascript Promise p settles and returns the value
+// it resolved to, or an error describing why it was rejected.
+func await(p js.Value) (v js.Value, err error) {
+	done := make(chan struct{})
+	then := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		v = args[0]
+		close(done)
+		return nil
+	})
+	defer then.Release()
+	catch := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		err = errors.New(args[0].Call("toString").String())
+		close(done)
+		return nil
+	})
+	defer catch.Release()
+	p.Call("then", then, catch)
+	<-done
+	return
+}
@@ -0,0 +1,67 @@
+// +build !tinygo
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// newServerTransport returns the Transport for talking to the server. TinyGo
+// builds use fetchTransport instead, because TinyGo's net/http is limited.
+func newServerTransport() Transport {
+	return httpTransport{}
+}
+
+// httpTransport is the Transport for a real server.
+type httpTransport struct{}
+
+// Get sends a /get request to the server.
+func (httpTransport) Get() (r Result) {
+	req, err := http.NewRequest("GET", serverURL+"/get", nil)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	r = doRequest(req, 500*time.Millisecond)
+	return
+}
+
+// Set posts a /set request to the server to change the value of a
+// parameter.
+func (httpTransport) Set(jsonData []byte) (r Result) {
+	req, err := http.NewRequest("POST", serverURL+"/set", bytes.NewBuffer(jsonData))
+	if err != nil {
+		r.Err = err
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	r = doRequest(req, 2*time.Second)
+	return
+}
+
+// doRequest sends req with the given timeout and reads the response into a
+// Result. Any status other than 200 is an error, described by the server's Err
+// message if it sent one.
+func doRequest(req *http.Request, timeout time.Duration) (r Result) {
+	client := &http.Client{Timeout: timeout}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	defer resp.Body.Close()
+	r.Body, err = ioutil.ReadAll(resp.Body)
+	r.Latency = time.Since(start)
+	r.Status = resp.Status
+	r.Code = resp.StatusCode
+	if err != nil {
+		r.Err = err
+		return
+	}
+	checkResponse(&r)
+	return
+}
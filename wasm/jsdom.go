@@ -0,0 +1,67 @@
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"syscall/js"
+)
+
+// NoDocumentError is returned if the global document is not available
+var NoDocumentError = errors.New("unable to get document object")
+
+// jsDOM implements DOM with syscall/js on the page's document.
+type jsDOM struct{}
+
+// getElementById is a wasm-side call to get a js Value by its id
+func getElementById(id string) (el js.Value, err error) {
+	jsDoc := js.Global().Get("document")
+	if !jsDoc.Truthy() {
+		err = NoDocumentError
+		return
+	}
+	el = jsDoc.Call("getElementById", id)
+	if !el.Truthy() {
+		err = fmt.Errorf("Unable to get element with id %s", id)
+	}
+	return
+}
+
+// Get returns the named property of the element with the given id. Boolean
+// and numeric properties are converted to their string forms.
+func (jsDOM) Get(id, prop string) (value string, err error) {
+	el, err := getElementById(id)
+	if err != nil {
+		return
+	}
+	v := el.Get(prop)
+	switch v.Type() {
+	case js.TypeBoolean:
+		value = strconv.FormatBool(v.Bool())
+	case js.TypeNumber:
+		value = strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case js.TypeString:
+		value = v.String()
+	default:
+		err = fmt.Errorf("element %s has no string, number or boolean %s", id, prop)
+	}
+	return
+}
+
+// Set assigns a string value to the named property of the element with the
+// given id. The checked property is boolean, so "true" and "false" are
+// converted.
+func (jsDOM) Set(id, prop, value string) (err error) {
+	el, err := getElementById(id)
+	if err != nil {
+		return
+	}
+	if prop == "checked" {
+		el.Set(prop, value == "true")
+		return
+	}
+	el.Set(prop, value)
+	return
+}
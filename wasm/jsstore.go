@@ -0,0 +1,37 @@
+// +build js,wasm
+
+package main
+
+import "syscall/js"
+
+// localStore implements Store with the page's localStorage. It keeps nothing
+// if the browser has storage disabled.
+type localStore struct{}
+
+// storage returns the page's localStorage. Merely reading the property throws
+// if storage is disabled, so it is read with Reflect.get, whose exceptions
+// syscall/js turns into panics.
+func storage() js.Value {
+	return js.Global().Get("Reflect").Call("get", js.Global(), "localStorage")
+}
+
+// Load returns the value saved under key, if any.
+func (localStore) Load(key string) (value string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			value, ok = "", false
+		}
+	}()
+	v := storage().Call("getItem", key)
+	if v.Type() != js.TypeString {
+		return
+	}
+	return v.String(), true
+}
+
+// Save saves value under key. Failures, e.g. when storage is full, are
+// ignored.
+func (localStore) Save(key, value string) {
+	defer func() { _ = recover() }()
+	storage().Call("setItem", key, value)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// LinkState describes the health of the connection to the server.
+type LinkState int
+
+const (
+	Connected LinkState = iota // the last get succeeded
+	Degraded                   // a few gets in a row have failed
+	Offline                    // offlineAfter or more gets in a row have failed
+)
+
+// String returns the name of s, which is its class in the link status
+// indicator and the key of the message that shows it.
+func (s LinkState) String() string {
+	switch s {
+	case Connected:
+		return "connected"
+	case Degraded:
+		return "degraded"
+	default:
+		return "offline"
+	}
+}
+
+// Polling and backoff settings
+var (
+	pollInterval = time.Second      // time between gets while connected
+	maxBackoff   = 30 * time.Second // longest time between gets while failing
+	offlineAfter = 3                // consecutive failures that mean offline
+	staleAfter   = 3 * time.Second  // age at which readouts are greyed
+)
+
+// link tracks consecutive get failures and the time of the last good get.
+type link struct {
+	failures int
+	lastGood time.Time // or of the values restored by restoreState
+	live     bool      // a get has succeeded since the client started
+	outcome  string    // the outcome of a get last shown in the status table
+	rnd      *rand.Rand
+	shown    string            // the state last shown, "" if none
+	written  map[string]string // values last written by update, by "id.prop"
+}
+
+// newLink returns a link that has yet to make contact.
+func newLink() *link {
+	return &link{rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// record notes the outcome of a get made at time now.
+func (l *link) record(ok bool, now time.Time) {
+	if ok {
+		l.failures = 0
+		l.lastGood = now
+		l.live = true
+		return
+	}
+	l.failures++
+}
+
+// state returns the current LinkState.
+func (l *link) state() LinkState {
+	switch {
+	case l.failures == 0:
+		return Connected
+	case l.failures < offlineAfter:
+		return Degraded
+	default:
+		return Offline
+	}
+}
+
+// stale reports whether the values from the last good get are older than
+// staleAfter at time now, or were restored rather than fetched.
+func (l *link) stale(now time.Time) bool {
+	return !l.live || now.Sub(l.lastGood) > staleAfter
+}
+
+// delay returns how long to wait before the next get. It doubles with each
+// consecutive failure up to maxBackoff, with up to 50% random jitter so that
+// many clients don't retry in lockstep when a server comes back.
+func (l *link) delay() time.Duration {
+	d := pollInterval
+	for i := 0; i < l.failures && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	if l.failures == 0 {
+		return d
+	}
+	return d/2 + time.Duration(l.rnd.Int63n(int64(d/2)+1))
+}
+
+// update sets the property of the element with the given id to value, unless
+// it was the last value update wrote there. LinkStatus and OfflineBanner are
+// live regions, and screen readers announce every write to them, changed or
+// not.
+func (l *link) update(id, prop, value string) {
+	key := id + "." + prop
+	if v, ok := l.written[key]; ok && v == value {
+		return
+	}
+	if l.written == nil {
+		l.written = map[string]string{}
+	}
+	if setElementAttributeById(id, prop, value) == nil {
+		l.written[key] = value
+	}
+}
+
+// showLink renders the link state into the link status indicator, logs any
+// change of state, greys the parameter readouts if they are stale and shows
+// the offline banner if they aren't live.
+func showLink(l *link, now time.Time) {
+	state := l.state()
+	if state.String() != l.shown {
+		logEvent(now, "link "+state.String())
+		l.shown = state.String()
+	}
+	l.update("LinkStatus", "textContent", msg(state.String()))
+	l.update("LinkStatus", "className", "LINK "+state.String())
+	class := "PARM"
+	if l.stale(now) {
+		class = "PARM STALE"
+	}
+	l.update("ParmTable", "className", class)
+	showOffline(l, now)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// localeFormat is how the client writes numbers, dates and its messages in a
+// locale. The generated locales_g.go holds one for each locale the page
+// offers.
+type localeFormat struct {
+	decimal  string // decimal separator
+	group    string // separator between groups of thousands, may be empty
+	date     string // time layout for dates
+	messages map[string]string
+}
+
+// locale is the locale the client writes in. Only the Server Interface
+// goroutine uses it, once it has started.
+var locale = locales[defaultLocale]
+
+// localeRequests holds the tag of the locale the page last chose, until the
+// Server Interface switches to it.
+var localeRequests = make(chan string, 1)
+
+// requestLocale asks the Server Interface to write in the locale with the
+// given tag from now on. A request it hasn't yet seen is replaced.
+func requestLocale(tag string) {
+	for {
+		select {
+		case localeRequests <- tag:
+			return
+		default:
+		}
+		select {
+		case <-localeRequests:
+		default:
+		}
+	}
+}
+
+// setLocale makes the locale with the given tag the one the client writes
+// in. Unknown tags are ignored.
+func setLocale(tag string) {
+	if f, ok := locales[tag]; ok {
+		locale = f
+	}
+}
+
+// msg returns the text of the message key in the current locale, with each
+// pair of args, a name and a value, replacing the name in braces.
+func msg(key string, args ...string) string {
+	text := locale.messages[key]
+	for i := 0; i+1 < len(args); i += 2 {
+		text = strings.Replace(text, "{"+args[i]+"}", args[i+1], -1)
+	}
+	return text
+}
+
+// formatFloat returns v with prec digits after the decimal separator of the
+// current locale, and its whole part in groups of thousands.
+func formatFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.ContainsAny(s, "NI") { // NaN or ±Inf
+		return s
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], locale.decimal+s[i+1:]
+	}
+	return groupThousands(whole) + frac
+}
+
+// formatInt returns v in groups of thousands as in the current locale.
+func formatInt(v int) string {
+	return groupThousands(strconv.Itoa(v))
+}
+
+// formatBool returns the word for v in the current locale.
+func formatBool(v bool) string {
+	return msg(strconv.FormatBool(v))
+}
+
+// groupThousands inserts the group separator of the current locale between
+// groups of three digits in digits, which may have a leading minus sign.
+func groupThousands(digits string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if locale.group == "" || len(digits) <= 3 {
+		return sign + digits
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	first := len(digits) % 3
+	if first == 0 {
+		first = 3
+	}
+	b.WriteString(digits[:first])
+	for i := first; i < len(digits); i += 3 {
+		b.WriteString(locale.group)
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
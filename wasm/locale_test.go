@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+func TestFormatNumbers(t *testing.T) {
+	defer setLocale(defaultLocale)
+	tests := []struct {
+		tag         string
+		v           float64
+		float, int_ string
+	}{
+		{"en", 1234567.891, "1,234,567.89", "1,234,567"},
+		{"en", -1234.5, "-1,234.50", "-1,234"},
+		{"en", 999, "999.00", "999"},
+		{"de", 1234567.891, "1.234.567,89", "1.234.567"},
+		{"de", -0.5, "-0,50", "0"},
+		{"fr", 12345.678, "12\u202f345,68", "12\u202f345"},
+	}
+	for _, tc := range tests {
+		setLocale(tc.tag)
+		if got := formatFloat(tc.v, 2); got != tc.float {
+			t.Errorf("%s: formatFloat(%v, 2) = %q, want %q", tc.tag, tc.v, got, tc.float)
+		}
+		if got := formatInt(int(tc.v)); got != tc.int_ {
+			t.Errorf("%s: formatInt(%d) = %q, want %q", tc.tag, int(tc.v), got, tc.int_)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	defer setLocale(defaultLocale)
+	setLocale("de")
+	if got, want := formatBool(true), "wahr"; got != want {
+		t.Errorf("formatBool(true) = %q, want %q", got, want)
+	}
+	if got, want := msg("offlineAt", "time", "09:30:00"), "Offline: Werte von 09:30:00 Uhr"; got != want {
+		t.Errorf("msg = %q, want %q", got, want)
+	}
+	setLocale("xx") // unknown, so still de
+	if got, want := msg("connected"), "verbunden"; got != want {
+		t.Errorf("msg after unknown locale = %q, want %q", got, want)
+	}
+}
+
+func TestRequestLocale(t *testing.T) {
+	requestLocale("de")
+	requestLocale("fr") // replaces the request not yet seen
+	if got := <-localeRequests; got != "fr" {
+		t.Errorf("expected the latest request, fr, got %s", got)
+	}
+}
+
+func TestSwitchLocaleOffline(t *testing.T) {
+	defer setLocale(defaultLocale)
+	fake := newFakeDOM(t)
+	dom = fake
+	SP.DirectUpdate(func(p *common.State) { p.Alpha = 1.5 })
+	defer SP.DirectUpdate(func(p *common.State) { *p = common.State{} })
+	testServer(t, http.StatusInternalServerError, `{"Err": "down"}`)
+	lnk := newLink()
+	// The readouts are rewritten even though the poll that follows fails.
+	before := len(trends["Alpha"])
+	switchLocale("de")
+	pollState(lnk)
+	fake.expect(t, "Alpha", "textContent", "1,50")
+	// Redrawing isn't a new sample.
+	if n := len(trends["Alpha"]); n != before {
+		t.Errorf("expected the Alpha trend to keep %d values, got %d", before, n)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// Store keeps small strings across visits to the page, e.g. in the browser's
+// localStorage.
+type Store interface {
+	Load(key string) (value string, ok bool)
+	Save(key, value string)
+}
+
+// store is where the client saves the last state it fetched. main sets it.
+var store Store = noStore{}
+
+// noStore is a Store that keeps nothing.
+type noStore struct{}
+
+func (noStore) Load(key string) (value string, ok bool) { return }
+func (noStore) Save(key, value string)                  {}
+
+// stateKey is the key in store of the last state fetched from the server.
+const stateKey = "lastState"
+
+// savedState is the last state fetched from the server and when it came. It
+// is saved as the JSON {"State":{...},"Time":"(RFC 3339 time)"}.
+type savedState struct {
+	Time  time.Time
+	State []byte
+}
+
+// encode returns the JSON for s.
+func (s savedState) encode() ([]byte, error) {
+	return common.EncodeObject(map[string]interface{}{
+		"State": common.RawJSON(s.State),
+		"Time":  s.Time.Format(time.RFC3339Nano),
+	})
+}
+
+// decode fills in s from the JSON in data.
+func (s *savedState) decode(data []byte) error {
+	members, err := objectMembers(data)
+	if err != nil {
+		return err
+	}
+	when, err := common.DecodeString(members["Time"])
+	if err != nil {
+		return err
+	}
+	s.Time, err = time.Parse(time.RFC3339Nano, when)
+	if err != nil {
+		return err
+	}
+	s.State = members["State"]
+	if s.State == nil {
+		return errors.New("no state saved")
+	}
+	return nil
+}
+
+// saveState saves State, fetched at time now, for restoreState. Simulated
+// states aren't saved, because they aren't the server's.
+func saveState(now time.Time) {
+	if _, sim := transport.(*simTransport); sim {
+		return
+	}
+	state, err := SP.Get().MarshalJSON()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	saved, err := savedState{now, state}.encode()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	store.Save(stateKey, string(saved))
+}
+
+// restoreState shows the state that saveState last saved, if any, so that a
+// client that starts without the server has something to show. It records
+// the time the values came from in lnk and marks them as not live. It fills
+// the parameters' input controls with them too.
+func restoreState(lnk *link) {
+	text, ok := store.Load(stateKey)
+	if !ok {
+		return
+	}
+	var saved savedState
+	mp := &common.State{}
+	err := saved.decode([]byte(text))
+	if err == nil {
+		err = mp.UnmarshalJSON(saved.State)
+	}
+	if err != nil {
+		fmt.Printf("couldn't restore the saved state: %v\n", err)
+		return
+	}
+	SP.DirectUpdate(func(p *common.State) { *p = *mp })
+	lnk.lastGood = saved.Time
+	UpdateParmReadouts()
+	subscribers.publish(stateMap(SP.Get()))
+	fillInputs()
+	lnk.update("ParmTable", "className", "PARM STALE")
+	showOffline(lnk, time.Now())
+}
+
+// showOffline shows or hides the offline banner. It is shown, giving the age
+// of the values on the page, while they aren't live: until the first get
+// succeeds and whenever the link is offline.
+func showOffline(lnk *link, now time.Time) {
+	if lnk.live && lnk.state() != Offline {
+		lnk.update("OfflineBanner", "className", "OFFLINE")
+		return
+	}
+	text := msg("offlineNone")
+	if !lnk.lastGood.IsZero() {
+		clock := lnk.lastGood.Format("15:04:05")
+		text = msg("offlineAt", "time", clock)
+		if lnk.lastGood.YearDay() != now.YearDay() || lnk.lastGood.Year() != now.Year() {
+			text = msg("offlineOn", "date", lnk.lastGood.Format(locale.date), "time", clock)
+		}
+	}
+	lnk.update("OfflineBanner", "textContent", text)
+	lnk.update("OfflineBanner", "className", "OFFLINE shown")
+}
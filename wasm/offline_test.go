@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// memStore is a Store in memory.
+type memStore map[string]string
+
+func (s memStore) Load(key string) (value string, ok bool) {
+	value, ok = s[key]
+	return
+}
+
+func (s memStore) Save(key, value string) {
+	s[key] = value
+}
+
+func TestOfflineStart(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	saved := memStore{}
+	store = saved
+	defer func() { store = noStore{} }()
+
+	// A visit with the server up saves the state.
+	testServer(t, http.StatusOK, `{"Alpha": 1.5, "Mode": "Manual"}`)
+	lnk := newLink()
+	restoreState(lnk) // nothing saved yet
+	fake.expect(t, "Alpha", "textContent", "")
+	pollState(lnk)
+	fake.expect(t, "OfflineBanner", "className", "OFFLINE")
+	if !strings.Contains(saved[stateKey], `"State":{"Alpha":1.5,`) {
+		t.Fatalf("expected the state to be saved, got %q", saved[stateKey])
+	}
+
+	// The next starts without it and shows the saved state as offline.
+	SP.DirectUpdate(func(p *common.State) { *p = common.State{} })
+	fake = newFakeDOM(t)
+	dom = fake
+	testServer(t, http.StatusInternalServerError, `{"Err": "down"}`)
+	lnk = newLink()
+	trends = trendSet{}
+	restoreState(lnk)
+	if n := len(trends["Alpha"]); n != 0 {
+		t.Errorf("expected the restored state not to be recorded in the trends, got %d values", n)
+	}
+	fake.expect(t, "Alpha", "textContent", "1.50")
+	fake.expect(t, "Mode", "textContent", "Manual")
+	fake.expect(t, "Mode-input", "value", "Manual")
+	fake.expect(t, "OfflineBanner", "className", "OFFLINE shown")
+	fake.expect(t, "ParmTable", "className", "PARM STALE")
+	msg, _ := fake.Get("OfflineBanner", "textContent")
+	if !strings.HasPrefix(msg, "Offline: showing the values received at ") {
+		t.Errorf("unexpected banner %q", msg)
+	}
+	pollState(lnk)
+	fake.expect(t, "OfflineBanner", "className", "OFFLINE shown")
+	fake.expect(t, "ParmTable", "className", "PARM STALE")
+
+	// The banner goes when the server answers, and comes back when the link
+	// goes offline.
+	testServer(t, http.StatusOK, `{"Alpha": 2}`)
+	pollState(lnk)
+	fake.expect(t, "OfflineBanner", "className", "OFFLINE")
+	testServer(t, http.StatusInternalServerError, `{"Err": "down"}`)
+	for i := 0; i < offlineAfter; i++ {
+		pollState(lnk)
+		if i < offlineAfter-1 {
+			fake.expect(t, "OfflineBanner", "className", "OFFLINE")
+		}
+	}
+	fake.expect(t, "OfflineBanner", "className", "OFFLINE shown")
+}
+
+func TestShowOfflineDate(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	lnk := newLink()
+	now := time.Date(2021, 3, 4, 12, 0, 0, 0, time.UTC)
+	showOffline(lnk, now)
+	fake.expect(t, "OfflineBanner", "textContent", "Offline: no values have been received from the server")
+	lnk.lastGood = time.Date(2021, 3, 2, 9, 30, 0, 0, time.UTC)
+	showOffline(lnk, now)
+	fake.expect(t, "OfflineBanner", "textContent", "Offline: showing the values received on Mar 2 at 09:30:00")
+}
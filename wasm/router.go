@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+)
+
+// showRoute shows the view named by hash, a URL fragment such as "#/logs",
+// hides the others and marks the view's link in the navigation bar as
+// active and as the current page. It shows the first view if hash names none
+// of them. It returns the route shown.
+func showRoute(hash string) (route string) {
+	route = strings.TrimPrefix(strings.TrimPrefix(hash, "#"), "/")
+	found := false
+	for _, r := range routes {
+		found = found || r == route
+	}
+	if !found {
+		route = routes[0]
+	}
+	for _, r := range routes {
+		view, nav, current := "VIEW inactive", "NAV", "false"
+		if r == route {
+			view, nav, current = "VIEW", "NAV active", "page"
+		}
+		_ = setElementAttributeById("View-"+r, "className", view)
+		_ = setElementAttributeById("Nav-"+r, "className", nav)
+		_ = setElementAttributeById("Nav-"+r, "ariaCurrent", current)
+	}
+	return
+}
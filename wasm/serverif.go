@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// global copy of state obtained periodically from server
+var State = common.State{}
+var SP = &State // a pointer to refer to the global state
+
+// checkSetRequest verifies that jsonData is an object with a single member
+// whose value is acceptable for the settable parameter it names. It returns
+// the name and the request re-encoded from the decoded value.
+func checkSetRequest(jsonData []byte) (name string, checked []byte, err error) {
+	objmap, err := objectMembers(jsonData)
+	if err != nil {
+		return
+	}
+	if len(objmap) != 1 {
+		err = fmt.Errorf("only one item per set request, please, found %d", len(objmap))
+		return
+	}
+	for n, rawval := range objmap {
+		var value interface{}
+		value, err = common.DecodeParm(n, rawval)
+		if err != nil {
+			return
+		}
+		name = n
+		checked, err = common.EncodeObject(map[string]interface{}{name: value})
+	}
+	return
+}
+
+// objectMembers returns the members of the JSON object in jsonData by name,
+// with their values undecoded. Like the rest of the client, it avoids
+// encoding/json, which relies on reflection that TinyGo supports poorly.
+func objectMembers(jsonData []byte) (objmap map[string][]byte, err error) {
+	objmap = map[string][]byte{}
+	err = common.ScanObject(jsonData, func(key string, value []byte) error {
+		objmap[key] = value
+		return nil
+	})
+	return
+}
+
+// setterQueue holds /set commands waiting to be sent to the server.
+var setterQueue = newSetQueue()
+
+// ServerInterface waits on setterQueue for changes to post to the server. When
+// no sets are waiting, it fetches State from the server once per second,
+// backing off while the server is unreachable. Readouts resynchronize with the
+// first successful fetch after the link recovers. Until the first fetch
+// succeeds, the page shows the last state saved by an earlier visit, if any.
+// When the page changes locale, the page is redrawn in it. It must be invoked
+// as a goroutine.
+func ServerInterface() {
+	lnk := newLink()
+	select {
+	case tag := <-localeRequests: // the page's locale at start
+		setLocale(tag)
+	default:
+	}
+	restoreState(lnk)
+	for {
+		select {
+		case <-setterQueue.ready:
+			for r := setterQueue.pop(); r != nil; r = setterQueue.pop() {
+				sendSet(r)
+			}
+		case tag := <-localeRequests:
+			switchLocale(tag)
+		case <-time.After(lnk.delay()):
+		}
+		// in any case update the state
+		pollState(lnk)
+	}
+}
+
+// switchLocale makes the locale with the given tag the one the client writes
+// in and rewrites the readouts in it at once, rather than waiting for a poll
+// that may fail. It records nothing in the trends.
+func switchLocale(tag string) {
+	setLocale(tag)
+	UpdateParmReadouts()
+}
+
+// pollState fetches State from the server, records the outcome in lnk and
+// updates the status table, link indicator, parameter readouts and the
+// history and logs views. It saves the state for restoreState. The first
+// state fetched also fills the parameters' input controls.
+func pollState(lnk *link) {
+	result := getStateFromServer()
+	// GetMsg is a live region, so leave it alone unless the outcome changes
+	if out := result.Outcome(); out != lnk.outcome {
+		showResult("GetMsg", result)
+		lnk.outcome = out
+	} else {
+		showLatency("GetMsg", result)
+	}
+	now := time.Now()
+	first := !lnk.live
+	lnk.record(result.Err == nil, now)
+	showLink(lnk, now)
+	if result.Err != nil {
+		fmt.Println(result.Err)
+		logEvent(now, "GET: "+result.String())
+		return
+	}
+	// Write new values to readouts in web page
+	RecordTrends()
+	UpdateParmReadouts()
+	recordHistory(now, SP.Get())
+	saveState(now)
+	// and tell JS subscribers what changed
+	subscribers.publish(stateMap(SP.Get()))
+	if first {
+		fillInputs() // later the user may be editing them
+	}
+}
+
+// sendSet posts a queued /set command, shows the outcome in the status table
+// and the logs view, and settles the Promises of everyone waiting on it.
+func sendSet(r *setRequest) {
+	result := transport.Set(r.jsonData)
+	showResult("SetMsg", result)
+	logEvent(time.Now(), "SET "+string(r.jsonData)+": "+result.String())
+	resp, _ := common.DecodeObject(result.Body) // nil if the body isn't a JSON object
+	if result.Err != nil {
+		fmt.Println(result.Err)
+	}
+	r.settle(resp, result.Err)
+}
+
+// showResult renders a request result into the status table: its outcome into
+// the cell with the given id, whose class marks it as succeeded or failed for
+// styling, and its latency into the cell beside it. Screen readers announce
+// the outcome.
+func showResult(id string, r Result) {
+	_ = setElementAttributeById(id, "textContent", r.Outcome())
+	class := "STATUS OK"
+	if r.Err != nil {
+		class = "STATUS FAIL"
+	}
+	_ = setElementAttributeById(id, "className", class)
+	showLatency(id, r)
+}
+
+// showLatency renders the latency of a request result into the status table
+// cell beside the one with the given id, or clears it if no response arrived.
+func showLatency(id string, r Result) {
+	latency := ""
+	if r.Code != 0 {
+		latency = r.Latency.Round(time.Millisecond).String()
+	}
+	_ = setElementAttributeById(id+"-time", "textContent", latency)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+)
+
+// settleFunc delivers the outcome of a /set command: the decoded server
+// response, if any, and an error unless the set succeeded.
+type settleFunc func(resp map[string]interface{}, err error)
+
+// setRequest is a /set command for one parameter waiting to be sent.
+type setRequest struct {
+	name     string
+	jsonData []byte
+	waiters  []settleFunc
+}
+
+// settle passes the outcome of r to everyone waiting on it.
+func (r *setRequest) settle(resp map[string]interface{}, err error) {
+	for _, w := range r.waiters {
+		w(resp, err)
+	}
+}
+
+// setQueue holds /set commands in the order they were made. Pushing a set for
+// a parameter that is already waiting replaces the waiting value, so repeated
+// sets of one parameter coalesce into a single request whose outcome goes to
+// every caller. Push never blocks.
+type setQueue struct {
+	mu      sync.Mutex
+	pending []*setRequest
+	ready   chan struct{} // receives a value when pending becomes non-empty
+}
+
+// newSetQueue returns an empty setQueue.
+func newSetQueue() *setQueue {
+	return &setQueue{ready: make(chan struct{}, 1)}
+}
+
+// push queues jsonData as the new value for name. done is called with the
+// outcome once the request has been sent.
+func (q *setQueue) push(name string, jsonData []byte, done settleFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, r := range q.pending {
+		if r.name == name {
+			r.jsonData = jsonData
+			r.waiters = append(r.waiters, done)
+			return
+		}
+	}
+	q.pending = append(q.pending, &setRequest{name: name, jsonData: jsonData, waiters: []settleFunc{done}})
+	select {
+	case q.ready <- struct{}{}:
+	default: // already signalled
+	}
+}
+
+// pop removes and returns the oldest waiting request, or nil if there is none.
+func (q *setQueue) pop() (r *setRequest) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return
+	}
+	r = q.pending[0]
+	q.pending = q.pending[1:]
+	return
+}
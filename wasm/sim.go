@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// simDefault selects simulation mode without the "sim" query parameter. The
+// sim build tag sets it.
+var simDefault = false
+
+// simTransport is a Transport that needs no server. It runs the simulated
+// back-end process from package common against a State of its own and
+// applies set requests to it with the same checks the server makes.
+type simTransport struct {
+	state common.State
+}
+
+// newSimTransport returns a simTransport whose simulation runs until the
+// program exits.
+func newSimTransport() *simTransport {
+	t := &simTransport{}
+	go func() {
+		for {
+			time.Sleep(common.SimInterval)
+			t.state.DirectUpdate(common.SimStep)
+		}
+	}()
+	return t
+}
+
+// Get returns the simulated state.
+func (t *simTransport) Get() (r Result) {
+	start := time.Now()
+	r.Body, r.Err = t.state.Get().MarshalJSON()
+	if r.Err != nil {
+		return
+	}
+	r.Status, r.Code, r.Latency = "200 OK (simulated)", statusOK, time.Since(start)
+	return
+}
+
+// Set applies a set request to the simulated state. Like the server's
+// setRequestHandler, it accepts one parameter per request and answers
+// {"Err":null} on success and {"Err":"message"} with status 400 otherwise.
+func (t *simTransport) Set(jsonData []byte) (r Result) {
+	start := time.Now()
+	objmap, err := objectMembers(jsonData)
+	if err == nil && len(objmap) != 1 {
+		err = fmt.Errorf("only one item per set request, please, found %d", len(objmap))
+	}
+	for name, rawval := range objmap {
+		if err == nil {
+			err = t.state.Apply(name, rawval)
+		}
+	}
+	r.Latency = time.Since(start)
+	if err != nil {
+		r.Status, r.Code = "400 Bad Request (simulated)", statusBadRequest
+		r.ServerErr = err.Error()
+		r.Body, _ = common.EncodeObject(map[string]interface{}{"Err": r.ServerErr})
+		r.Err = err
+		return
+	}
+	r.Status, r.Code = "200 OK (simulated)", statusOK
+	r.Body = []byte(`{"Err":null}`)
+	return
+}
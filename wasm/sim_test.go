@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSimTransport(t *testing.T) {
+	sim := &simTransport{} // no simulation goroutine, so the state holds still
+	tests := []struct {
+		req  string
+		code int
+		err  string
+	}{
+		{`{"Gamma": 50}`, http.StatusOK, ""},
+		{`{"Gamma": 500}`, http.StatusBadRequest, "Gamma must be between 0 and 100, got 500"},
+		{`{"Alpha": 1}`, http.StatusBadRequest, "Alpha is not settable"},
+		{`{"Gamma": 1, "Zeta": 2}`, http.StatusBadRequest, "only one item per set request, please, found 2"},
+	}
+	for _, tc := range tests {
+		r := sim.Set([]byte(tc.req))
+		if r.Code != tc.code || r.ServerErr != tc.err {
+			t.Errorf("%s: expected %d %q, got %d %q", tc.req, tc.code, tc.err, r.Code, r.ServerErr)
+		}
+	}
+	r := sim.Get()
+	var state map[string]interface{}
+	if err := json.Unmarshal(r.Body, &state); err != nil || r.Err != nil {
+		t.Fatalf("unexpected error %v, %v", err, r.Err)
+	}
+	if state["Gamma"] != 50.0 {
+		t.Errorf("expected Gamma to be 50 after set, got %v", state["Gamma"])
+	}
+}
@@ -0,0 +1,8 @@
+// +build sim
+
+package main
+
+// Building with -tags sim makes simulation mode the default.
+func init() {
+	simDefault = true
+}
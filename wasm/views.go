@@ -0,0 +1,56 @@
+package main
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+// Number of rows kept by the history and logs views
+const (
+	historyLength = 50
+	logLength     = 100
+)
+
+// recentRows holds the most recent rows of a view's table or list, newest
+// first.
+type recentRows struct {
+	rows []string
+	max  int
+}
+
+// add puts row at the top, drops the oldest row if there are more than max,
+// and returns the HTML for all the rows.
+func (r *recentRows) add(row string) string {
+	r.rows = append([]string{row}, r.rows...)
+	if len(r.rows) > r.max {
+		r.rows = r.rows[:r.max]
+	}
+	return strings.Join(r.rows, "\n")
+}
+
+var (
+	history = &recentRows{max: historyLength} // rows of the history view
+	logbook = &recentRows{max: logLength}     // entries in the logs view
+)
+
+// recordHistory adds the values in s, fetched at time now, to the history
+// view.
+func recordHistory(now time.Time, s *common.State) {
+	var b strings.Builder
+	b.WriteString("<tr><td>" + now.Format("15:04:05") + "</td>")
+	for _, cell := range historyCells(s) {
+		b.WriteString("<td>" + html.EscapeString(cell) + "</td>")
+	}
+	b.WriteString("</tr>")
+	_ = setElementAttributeById("HistoryRows", "innerHTML", history.add(b.String()))
+}
+
+// logEvent adds msg, about something that happened at time now, to the logs
+// view.
+func logEvent(now time.Time, msg string) {
+	entry := "<li>" + now.Format("15:04:05") + " " + html.EscapeString(msg) + "</li>"
+	_ = setElementAttributeById("LogList", "innerHTML", logbook.add(entry))
+}
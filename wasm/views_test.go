@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Michael-F-Ellis/wasmskel/common"
+)
+
+func TestShowRoute(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	last := routes[len(routes)-1]
+	for hash, want := range map[string]string{"#/" + last: last, "#" + last: last, "": routes[0], "#/nope": routes[0]} {
+		if got := showRoute(hash); got != want {
+			t.Errorf("showRoute(%q) = %q, want %q", hash, got, want)
+		}
+		for _, r := range routes {
+			view, nav, current := "VIEW inactive", "NAV", "false"
+			if r == want {
+				view, nav, current = "VIEW", "NAV active", "page"
+			}
+			fake.expect(t, "View-"+r, "className", view)
+			fake.expect(t, "Nav-"+r, "className", nav)
+			fake.expect(t, "Nav-"+r, "ariaCurrent", current)
+		}
+	}
+}
+
+func TestRecentRows(t *testing.T) {
+	r := &recentRows{max: 2}
+	r.add("a")
+	r.add("b")
+	if got := r.add("c"); got != "c\nb" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestHistoryAndLogs(t *testing.T) {
+	fake := newFakeDOM(t)
+	dom = fake
+	history = &recentRows{max: historyLength}
+	logbook = &recentRows{max: logLength}
+	now := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	recordHistory(now, &common.State{Mode: "<Off>"})
+	rows, _ := fake.Get("HistoryRows", "innerHTML")
+	if !strings.HasPrefix(rows, "<tr><td>15:04:05</td>") || !strings.Contains(rows, "<td>&lt;Off&gt;</td>") {
+		t.Errorf("unexpected history %q", rows)
+	}
+	logEvent(now, "first")
+	logEvent(now, "second")
+	fake.expect(t, "LogList", "innerHTML", "<li>15:04:05 second</li>\n<li>15:04:05 first</li>")
+}
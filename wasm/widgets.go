@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+// fraction returns where v lies between min and max, from 0 to 1.
+func fraction(v, min, max float64) float64 {
+	if !(max > min) || math.IsNaN(v) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (v-min)/(max-min)))
+}
+
+// gaugeSVG returns the SVG for a circular gauge showing v on a half circle
+// scaled from min to max, with text in the middle.
+func gaugeSVG(v, min, max float64, text string) string {
+	// The arc is centred on (50, 50) with radius 40 and runs clockwise from
+	// the left.
+	angle := math.Pi * fraction(v, min, max)
+	x, y := 50-40*math.Cos(angle), 50-40*math.Sin(angle)
+	return `<svg viewBox="0 0 100 60" role="img" aria-label="` + html.EscapeString(text) + `">` +
+		`<path class="track" stroke-width="8" d="M10,50 A40,40 0 0 1 90,50"/>` +
+		fmt.Sprintf(`<path class="fill" stroke-width="8" fill="none" d="M10,50 A40,40 0 0 1 %.1f,%.1f"/>`, x, y) +
+		`<text x="50" y="48" text-anchor="middle" font-size="14">` + html.EscapeString(text) + `</text></svg>`
+}
+
+// barSVG returns the SVG for a horizontal bar filled in proportion to where v
+// lies between min and max, with text at the right.
+func barSVG(v, min, max float64, text string) string {
+	return `<svg viewBox="0 0 100 14" role="img" aria-label="` + html.EscapeString(text) + `">` +
+		`<rect class="track" x="0.5" y="0.5" width="69" height="13"/>` +
+		fmt.Sprintf(`<rect class="fill" x="1" y="1" width="%.1f" height="12"/>`, 68*fraction(v, min, max)) +
+		`<text x="99" y="11" text-anchor="end" font-size="11">` + html.EscapeString(text) + `</text></svg>`
+}
+
+// lampSVG returns the SVG for a status lamp, lit if on is true.
+func lampSVG(on bool) string {
+	class, label := "track", msg("off")
+	if on {
+		class, label = "lit", msg("on")
+	}
+	return `<svg viewBox="0 0 16 16" role="img" aria-label="` + html.EscapeString(label) + `"><circle class="` + class + `" cx="8" cy="8" r="6" stroke="currentColor"/></svg>`
+}
+
+// trendLength is the number of recent values a trend widget shows.
+const trendLength = 60
+
+// trendSet holds the recent values of the parameters shown by trend widgets.
+// It is used only by RecordTrends and UpdateParmReadouts, in the Server
+// Interface goroutine.
+type trendSet map[string][]float64
+
+// trends holds the values for the page's trend widgets.
+var trends = trendSet{}
+
+// add records v as the latest value of the named parameter.
+func (ts trendSet) add(name string, v float64) {
+	values := append(ts[name], v)
+	if len(values) > trendLength {
+		values = values[len(values)-trendLength:]
+	}
+	ts[name] = values
+}
+
+// svg returns the SVG for the trend chart of the named parameter, or nothing
+// if no values have been recorded. The chart is scaled from min to max if
+// min < max, otherwise to the range of the values shown.
+func (ts trendSet) svg(name string, min, max float64) string {
+	if len(ts[name]) == 0 {
+		return ""
+	}
+	return trendSVG(ts[name], min, max)
+}
+
+// trendSVG returns an SVG line chart of values, scaled from min to max if
+// min < max, otherwise to the range of values. It is 100 units wide with the
+// oldest value at the left, and 20 units high.
+func trendSVG(values []float64, min, max float64) string {
+	if min >= max {
+		min, max = values[0], values[0]
+		for _, v := range values {
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+	}
+	points := make([]string, len(values))
+	for i, v := range values {
+		y := 10.0 // a flat line through the middle if there is no range
+		if max > min {
+			if v < min {
+				v = min
+			} else if v > max {
+				v = max
+			}
+			y = 20 * (max - v) / (max - min)
+		}
+		x := 100 * float64(i) / float64(trendLength-1)
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return `<svg viewBox="0 0 100 20" preserveAspectRatio="none">` +
+		`<polyline fill="none" stroke="currentColor" stroke-width="1" vector-effect="non-scaling-stroke" points="` +
+		strings.Join(points, " ") + `"/></svg>`
+}
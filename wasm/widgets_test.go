@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGaugeSVG(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string // end of the filled arc
+	}{
+		{0, "10.0,50.0"},
+		{50, "50.0,10.0"},
+		{100, "90.0,50.0"},
+		{150, "90.0,50.0"}, // clipped
+		{-5, "10.0,50.0"},
+	}
+	for _, tc := range tests {
+		got := gaugeSVG(tc.v, 0, 100, "x")
+		if !strings.Contains(got, "0 0 1 "+tc.want+`"/>`) {
+			t.Errorf("gaugeSVG(%v) = %s, expected the arc to end at %s", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestBarSVG(t *testing.T) {
+	got := barSVG(2.5, 0, 10, "<2.5>")
+	for _, want := range []string{`class="fill" x="1" y="1" width="17.0"`, ">&lt;2.5&gt;</text>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("barSVG = %s, expected %s", got, want)
+		}
+	}
+}
+
+func TestLampSVG(t *testing.T) {
+	if !strings.Contains(lampSVG(true), `class="lit"`) || strings.Contains(lampSVG(false), `class="lit"`) {
+		t.Errorf("got %s and %s", lampSVG(true), lampSVG(false))
+	}
+}
+
+func TestTrendSVG(t *testing.T) {
+	tests := []struct {
+		values   []float64
+		min, max float64
+		want     string
+	}{
+		{[]float64{0, 5, 10, 20}, 0, 10, `points="0.0,20.0 1.7,10.0 3.4,0.0 5.1,0.0"`}, // scaled to bounds and clipped
+		{[]float64{2, 4}, 0, 0, `points="0.0,20.0 1.7,0.0"`},                           // scaled to the values
+		{[]float64{3, 3}, 0, 0, `points="0.0,10.0 1.7,10.0"`},                          // flat
+	}
+	for _, tc := range tests {
+		got := trendSVG(tc.values, tc.min, tc.max)
+		if !strings.Contains(got, tc.want) || !strings.HasPrefix(got, "<svg") {
+			t.Errorf("trendSVG(%v, %v, %v) = %s, expected %s", tc.values, tc.min, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestTrendSetKeepsRecentValues(t *testing.T) {
+	ts := trendSet{}
+	for i := 0; i < trendLength+10; i++ {
+		ts.add("Alpha", float64(i))
+	}
+	values := ts["Alpha"]
+	if len(values) != trendLength || values[0] != 10 {
+		t.Errorf("expected the last %d values, got %d starting with %v", trendLength, len(values), values[0])
+	}
+}
@@ -3,186 +3,306 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net/url"
+	"strings"
 	"syscall/js"
-	"time"
-
-	"github.com/Michael-F-Ellis/wasmskel/common"
 )
 
-// global copy of state obtained periodically from server
-var State = common.State{}
-var SP = &State // a pointer to refer to the global state
-
-// A few predefined errors
-// An Http error that actually indicates success
-var Http200Error = fmt.Errorf("%d OK", http.StatusOK)
-
-// NoDocumentError is returned if the global document is not available
-var NoDocumentError = errors.New("unable to get document object")
-
-// Main exports a setter function that can be called from javascript.
-// Then it launches the Server Interface as a goroutine and finally
-// waits forever on an empty select.
+// Main exports setter and state access functions that can be called from
+// javascript, shows the view named in the URL, follows the page's locale and
+// wires the page's controls or, when running in a Web Worker, listens for
+// requests from the page. Then it launches the Server Interface as a
+// goroutine and finally waits forever on an empty select.
 func main() {
 	fmt.Println("Go Web Assembly") // fmt.Print outputs go to the js console.
+	if simDefault || simRequested() {
+		fmt.Println("Simulation mode: no server needed")
+		transport = newSimTransport()
+	}
+	if inWorker() {
+		// The page script provides the javascript functions
+		dom = workerDOM{}
+		fillInputs = fillPageInputs
+		store = workerStore{}
+		serveWorker()
+		go ServerInterface()
+		select {}
+	}
+	dom = jsDOM{}
+	store = localStore{}
 	js.Global().Set("Setter", SetterWrapper())
+	js.Global().Set("SetParm", SetParmWrapper())
+	js.Global().Set("GetParm", GetParmWrapper())
+	js.Global().Set("GetState", GetStateWrapper())
+	js.Global().Set("Subscribe", SubscribeWrapper())
+	js.Global().Set("Unsubscribe", UnsubscribeWrapper())
+	watchRoute()
+	watchLocale()
+	wireControls()
 	go ServerInterface()
 	select {}
 }
 
 // SetterWrapper exports a function that allows javascript to enqueue json
-// messages to be sent to the server as /set commands.
+// messages to be sent to the server as /set commands. The message must hold
+// exactly one settable parameter with a valid value. The function returns a
+// Promise that resolves with the decoded server response, or rejects with an
+// object whose Err member describes the failure.
 func SetterWrapper() (jsf js.Func) {
 	jsf = js.FuncOf(
 		func(this js.Value, args []js.Value) (result interface{}) {
 			if len(args) != 1 {
-				result := map[string]interface{}{
-					"error": "Invalid no of arguments passed",
-				}
-				return result
+				return setterError(errors.New("Invalid no of arguments passed"))
 			}
-			setterChan <- []byte(args[0].String())
-			return
+			name, jsonData, err := checkSetRequest([]byte(args[0].String()))
+			if err != nil {
+				return setterError(err)
+			}
+			return enqueueSet(name, jsonData)
 		},
 	)
 	return
 }
 
-// getElementById is a wasm-side call to get a js Value by its id
-func getElementById(id string) (el js.Value, err error) {
-	jsDoc := js.Global().Get("document")
-	if !jsDoc.Truthy() {
-		err = NoDocumentError
-		return
-	}
-	el = jsDoc.Call("getElementById", id)
-	if !el.Truthy() {
-		err = fmt.Errorf("Unable to get element with id %s", id)
-	}
+// SetParmWrapper exports a function that reads the input control for the
+// named parameter, checks the value against the schema and enqueues a /set
+// command for it. Like Setter, it returns a Promise.
+func SetParmWrapper() (jsf js.Func) {
+	jsf = js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			if len(args) != 1 {
+				return setterError(errors.New("Invalid no of arguments passed"))
+			}
+			return setParm(args[0].String())
+		},
+	)
 	return
 }
 
-// setElementAttributeById assigns a string value to a DOM element
-// with the given id.
-func setElementAttributeById(id, attr, value string) (err error) {
-	el, err := getElementById(id)
+// setParm reads the input control for the named parameter and enqueues a /set
+// command for its value. It returns a Promise for the result.
+func setParm(name string) js.Value {
+	jsonData, err := readParmInput(name)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return setterError(err)
 	}
-	el.Set(attr, value)
+	return enqueueSet(name, jsonData)
+}
+
+// GetParmWrapper exports a function that returns the latest value of the
+// named parameter, or undefined if there is no such parameter.
+func GetParmWrapper() (jsf js.Func) {
+	jsf = js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			if len(args) != 1 {
+				return js.Undefined()
+			}
+			value, ok := stateMap(SP.Get())[args[0].String()]
+			if !ok {
+				return js.Undefined()
+			}
+			return value
+		},
+	)
 	return
 }
 
-// setterChan accepts json byte strings to be sent to the server.
-var setterChan = make(chan []byte, 1)
+// GetStateWrapper exports a function that returns the latest State as an
+// object keyed by parameter name.
+func GetStateWrapper() (jsf js.Func) {
+	jsf = js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			return stateMap(SP.Get())
+		},
+	)
+	return
+}
 
-// ServerInterface listens on setterChan for changes to post to the server. When
-// no messages are available on the channel, it fetches State from the server
-// once per second. It must be invoked as a goroutine.
-func ServerInterface() {
-	for {
-		var err error
-		select {
-		case jsonData := <-setterChan:
-			err = SetFloat(jsonData, "/set", 2)
-			_ = setElementAttributeById("SetMsg", "textContent", err.Error())
+// SubscribeWrapper exports a function Subscribe(names, callback). The callback
+// is called with an object holding the current values of those parameters in
+// names, a string or an array of strings, if State has been fetched, and then
+// with their new values whenever they change. An empty array subscribes to
+// every parameter. Subscribe returns an id to pass to Unsubscribe.
+func SubscribeWrapper() (jsf js.Func) {
+	jsf = js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			if len(args) != 2 || args[1].Type() != js.TypeFunction {
+				fmt.Println("Subscribe: expected names and a callback function")
+				return js.Undefined()
+			}
+			names, err := subscribeNames(args[0])
 			if err != nil {
-				fmt.Println(err)
+				fmt.Println("Subscribe:", err)
+				return js.Undefined()
 			}
-		case <-time.After(time.Second):
-		}
-		// in either case update the state
-		_, err = getStateFromServer() // always returns an error even on success so we can update status line
-		_ = setElementAttributeById("GetMsg", "textContent", err.Error())
-		if err != Http200Error {
-			fmt.Println(err)
-			continue
+			callback := args[1]
+			return subscribers.add(names, func(changes map[string]interface{}) {
+				callback.Invoke(changes)
+			})
+		},
+	)
+	return
+}
+
+// subscribeNames returns the parameter names in the names argument of
+// Subscribe, a string or an array of strings.
+func subscribeNames(arg js.Value) (names []string, err error) {
+	switch {
+	case arg.Type() == js.TypeString:
+		names = append(names, arg.String())
+	case js.Global().Get("Array").Call("isArray", arg).Bool():
+		for i := 0; i < arg.Length(); i++ {
+			names = append(names, arg.Index(i).String())
 		}
-		// Write new values to readouts in web page
-		UpdateParmReadouts()
+	default:
+		err = errors.New("expected a name or an array of names")
 	}
+	return
 }
 
-// getStateFromServer fetches the current values in State from the server and
-// updates a local copy. It also returns the JSON byte slice that came from
-// the server.
-func getStateFromServer() (jbytes []byte, err error) {
-	// Compose the request
-	req, err := http.NewRequest("GET", "/get", nil)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	// Send the request
-	client := &http.Client{}
-	client.Timeout = 500 * time.Millisecond
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	defer resp.Body.Close()
+// UnsubscribeWrapper exports a function that cancels the subscription with
+// the id returned by Subscribe.
+func UnsubscribeWrapper() (jsf js.Func) {
+	jsf = js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			if len(args) == 1 {
+				subscribers.remove(args[0].Int())
+			}
+			return
+		},
+	)
+	return
+}
 
-	// Read and check status of the response
-	jbytes, _ = ioutil.ReadAll(resp.Body)
-	switch resp.StatusCode {
-	case http.StatusOK:
-		err = Http200Error // actually success
-	default:
-		err = fmt.Errorf("%s: %s", resp.Status, string(jbytes))
-		fmt.Printf("%v", err) // also log it to the console
-	}
+// watchRoute shows the view named by the URL fragment, now and whenever the
+// fragment changes.
+func watchRoute() {
+	location := js.Global().Get("location")
+	js.Global().Call("addEventListener", "hashchange", js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			showRoute(location.Get("hash").String())
+			return
+		},
+	))
+	showRoute(location.Get("hash").String())
+}
+
+// watchLocale has the client write in the locale of the page, which i18n.js
+// sets in the lang attribute of the document before the client starts, and
+// in each locale the user chooses after.
+func watchLocale() {
+	requestLocale(js.Global().Get("document").Get("documentElement").Get("lang").String())
+	js.Global().Call("addEventListener", "localechange", js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			requestLocale(args[0].Get("detail").String())
+			return
+		},
+	))
+}
 
-	// Decode the response and update the global state
-	mp := &common.State{}
-	e := json.Unmarshal(jbytes, mp)
-	if e != nil {
-		err = fmt.Errorf("%v", e)
-		fmt.Println(err)
-		return
+// wireControls makes each Set button on the page call setParm for the
+// parameter named by its data-parm attribute. In the parameter's input
+// control, Enter does the same and Escape restores the current value. The
+// page has no inline event handlers, so that it can be served with a strict
+// Content-Security-Policy.
+func wireControls() {
+	// setParm shows failures in the status table, so the controls needn't
+	// handle the rejection of the Promise it returns.
+	ignore := js.FuncOf(func(this js.Value, args []js.Value) interface{} { return nil })
+	doc := js.Global().Get("document")
+	buttons := doc.Call("querySelectorAll", "button[data-parm]")
+	for i := 0; i < buttons.Length(); i++ {
+		name := buttons.Index(i).Get("dataset").Get("parm").String()
+		buttons.Index(i).Call("addEventListener", "click", js.FuncOf(
+			func(this js.Value, args []js.Value) (result interface{}) {
+				setParm(name).Call("catch", ignore)
+				return
+			},
+		))
+		input := doc.Call("getElementById", name+"-input")
+		if !input.Truthy() {
+			continue
+		}
+		input.Call("addEventListener", "keydown", js.FuncOf(
+			func(this js.Value, args []js.Value) (result interface{}) {
+				switch args[0].Get("key").String() {
+				case "Enter":
+					args[0].Call("preventDefault")
+					setParm(name).Call("catch", ignore)
+				case "Escape":
+					args[0].Call("preventDefault")
+					restoreInput(input, name)
+				}
+				return
+			},
+		))
 	}
-	SP.DirectUpdate(func(p *common.State) { *p = *mp })
-	return
 }
 
-// SetFloat posts a /set request to the server to change the value of a float
-// parameter. It always returns an error, which will be Http200Error when the
-// request is successful.
-func SetFloat(jsonData []byte, url string, timeout int64) (err error) {
-	// compose the request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return
+// restoreInput sets input, the control for the named parameter, to the
+// parameter's current value.
+func restoreInput(input js.Value, name string) {
+	v, ok := stateMap(SP.Get())[name]
+	switch {
+	case !ok:
+	case input.Get("type").String() == "checkbox":
+		input.Set("checked", v == true)
+	default:
+		input.Set("value", fmt.Sprint(v))
 	}
-	req.Header.Set("Content-Type", "application/json")
-	//fmt.Println("Request Header:", req.Header)
-	fmt.Println("Request Body:", req.Body)
-
-	// Send the request
-	client := &http.Client{}
-	client.Timeout = time.Duration(timeout * 1e9) // nanoseconds, hence the 1e9 to get seconds
-	resp, err := client.Do(req)
+}
+
+// simRequested reports whether the page URL has a "sim" query parameter, e.g.
+// http://localhost:9090/?sim
+func simRequested() bool {
+	search := js.Global().Get("location").Get("search").String()
+	q, err := url.ParseQuery(strings.TrimPrefix(search, "?"))
 	if err != nil {
-		fmt.Printf("%v", err) // also log it to the console
-		return
+		return false
 	}
-	defer resp.Body.Close()
+	_, ok := q["sim"]
+	return ok
+}
 
-	// Read and check the response.
-	body, _ := ioutil.ReadAll(resp.Body)
-	switch resp.StatusCode {
-	case http.StatusOK:
-		err = Http200Error // actually success
-	default:
-		err = fmt.Errorf("%s: %s: %s", resp.Status, string(jsonData), string(body))
-		fmt.Printf("%v", err) // also log it to the console
+// enqueueSet queues a /set command and returns a Promise for its result.
+func enqueueSet(name string, jsonData []byte) js.Value {
+	p, settle := newPromise()
+	setterQueue.push(name, jsonData, settle)
+	return p
+}
+
+// setterError shows a rejected set request in the status table and returns
+// a Promise rejected with it to the javascript caller.
+func setterError(err error) js.Value {
+	fmt.Println(err)
+	showResult("SetMsg", Result{Err: err})
+	p, settle := newPromise()
+	settle(nil, err)
+	return p
+}
+
+// newPromise returns a new JS Promise and the function that settles it. A nil
+// err resolves the Promise with resp. Otherwise it is rejected with resp, or
+// with {Err: err.Error()} if resp is nil.
+func newPromise() (p js.Value, settle settleFunc) {
+	var resolve, reject js.Value
+	executor := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject = args[0], args[1]
+		return nil
+	})
+	p = js.Global().Get("Promise").New(executor) // runs executor immediately
+	executor.Release()
+	settle = func(resp map[string]interface{}, err error) {
+		if err == nil {
+			resolve.Invoke(js.ValueOf(resp))
+			return
+		}
+		if resp == nil {
+			resp = map[string]interface{}{"Err": err.Error()}
+		}
+		reject.Invoke(js.ValueOf(resp))
 	}
 	return
 }
@@ -0,0 +1,11 @@
+// +build !js !wasm
+
+package main
+
+import "log"
+
+// The client only runs in a browser. This main lets the package build, and its
+// tests run, on other platforms.
+func main() {
+	log.Fatal("the wasm client must be built with GOOS=js GOARCH=wasm")
+}
@@ -0,0 +1,34 @@
+// +build js,wasm
+
+package main
+
+import (
+	"reflect"
+	"syscall/js"
+	"testing"
+)
+
+// Run with GOOS=js GOARCH=wasm go test, with $(go env GOROOT)/lib/wasm on the
+// PATH so that go test runs the tests in node.
+
+func TestSubscribeNames(t *testing.T) {
+	for _, c := range []struct {
+		arg  interface{}
+		want []string
+	}{
+		{"Alpha", []string{"Alpha"}},
+		{[]interface{}{"Alpha", "Beta"}, []string{"Alpha", "Beta"}},
+		{[]interface{}{}, nil},
+	} {
+		names, err := subscribeNames(js.ValueOf(c.arg))
+		if err != nil || !reflect.DeepEqual(names, c.want) {
+			t.Errorf("subscribeNames(%v) gave %q, %v, want %q", c.arg, names, err, c.want)
+		}
+	}
+	// A plain object has no length, and mustn't crash the client.
+	for _, arg := range []interface{}{map[string]interface{}{}, 42} {
+		if names, err := subscribeNames(js.ValueOf(arg)); err == nil {
+			t.Errorf("subscribeNames(%v) gave %q, want an error", arg, names)
+		}
+	}
+}
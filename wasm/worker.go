@@ -0,0 +1,116 @@
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+// inWorker reports whether the client is running in a Web Worker, where there
+// is no document.
+func inWorker() bool {
+	return !js.Global().Get("document").Truthy()
+}
+
+// postMessage sends msg to the page.
+func postMessage(msg map[string]interface{}) {
+	js.Global().Call("postMessage", msg)
+}
+
+// workerDOM implements DOM in a Web Worker by posting each change to the page,
+// whose script applies it. The page can't be read from a worker, so the page
+// script sends the text of input controls along with set requests instead.
+type workerDOM struct{}
+
+// Get always fails.
+func (workerDOM) Get(id, prop string) (value string, err error) {
+	err = errors.New("the page can't be read from a Web Worker")
+	return
+}
+
+// Set posts a "dom" message asking the page to make the change.
+func (workerDOM) Set(id, prop, value string) (err error) {
+	postMessage(map[string]interface{}{"type": "dom", "id": id, "prop": prop, "value": value})
+	return
+}
+
+// fillPageInputs posts a "fill" message asking the page to set the input
+// controls from the values it has been sent, which it does as it would on
+// Escape.
+func fillPageInputs() {
+	postMessage(map[string]interface{}{"type": "fill"})
+}
+
+// workerStore implements Store in a Web Worker, which has no localStorage, by
+// asking the page to save values. The page sends the values it has saved in
+// "restore" messages when it starts the worker.
+type workerStore map[string]string
+
+// Load returns the value the page sent for key, if any.
+func (s workerStore) Load(key string) (value string, ok bool) {
+	value, ok = s[key]
+	return
+}
+
+// Save posts a "store" message asking the page to save value under key.
+func (s workerStore) Save(key, value string) {
+	s[key] = value
+	postMessage(map[string]interface{}{"type": "store", "key": key, "value": value})
+}
+
+// serveWorker installs the handler for set requests, route changes, locale
+// changes and restored values from the page, arranges for state changes to be
+// posted to the page, and tells the worker script to deliver any messages that
+// arrived while the client was starting. See
+// gen/worker.go for the message protocol.
+func serveWorker() {
+	js.Global().Set("onmessage", js.FuncOf(
+		func(this js.Value, args []js.Value) (result interface{}) {
+			m := args[0].Get("data")
+			switch m.Get("type").String() {
+			case "route":
+				showRoute(m.Get("hash").String())
+				return
+			case "restore":
+				store.(workerStore)[m.Get("key").String()] = m.Get("value").String()
+				return
+			case "locale":
+				requestLocale(m.Get("tag").String())
+				return
+			}
+			seq := m.Get("seq").Int()
+			reply := func(resp map[string]interface{}, err error) {
+				if err != nil && resp == nil {
+					resp = map[string]interface{}{"Err": err.Error()}
+				}
+				postMessage(map[string]interface{}{"type": "result", "seq": seq, "ok": err == nil, "resp": resp})
+			}
+			var name string
+			var jsonData []byte
+			var err error
+			switch m.Get("type").String() {
+			case "setparm":
+				name = m.Get("name").String()
+				jsonData, err = parmRequest(name, m.Get("text").String())
+			case "setter":
+				name, jsonData, err = checkSetRequest([]byte(m.Get("json").String()))
+			default:
+				err = fmt.Errorf("unknown message type %s", m.Get("type").String())
+			}
+			if err != nil {
+				fmt.Println(err)
+				showResult("SetMsg", Result{Err: err})
+				reply(nil, err)
+				return
+			}
+			setterQueue.push(name, jsonData, reply)
+			return
+		},
+	))
+	subscribers.add(nil, func(changes map[string]interface{}) {
+		postMessage(map[string]interface{}{"type": "state", "changes": changes})
+	})
+	js.Global().Call("wasmReady")
+}